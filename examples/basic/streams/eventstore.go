@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// replayPageSize is how many events Rebuild reads per XRANGE call
+const replayPageSize = 100
+
+// typeField is the stream field holding Event.Type, so Data can't use it
+const typeField = "event"
+
+// Event is one entry in an aggregate's history
+type Event struct {
+	ID   string            // Stream entry ID (set when read back)
+	Type string            // e.g. "user.created"
+	Data map[string]string // Event fields (must not include typeField)
+}
+
+// EventStore keeps one stream per aggregate, e.g. with prefix "user":
+//
+//	user:123:events   STREAM   every event for user 123, in order
+type EventStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewEventStore(client *redis.Client, prefix string) *EventStore {
+	return &EventStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *EventStore) streamKey(aggregateID string) string {
+	return s.prefix + ":" + aggregateID + ":events"
+}
+
+// Append records an event for the aggregate and returns its stream ID.
+// Data may not contain the reserved field typeField ("event").
+func (s *EventStore) Append(ctx context.Context, aggregateID string, event Event) (string, error) {
+	if _, ok := event.Data[typeField]; ok {
+		return "", fmt.Errorf("event %s: data field %q is reserved for the event type", event.Type, typeField)
+	}
+
+	values := make(map[string]interface{}, len(event.Data)+1)
+	for k, v := range event.Data {
+		values[k] = v
+	}
+	values[typeField] = event.Type
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.streamKey(aggregateID),
+		Values: values,
+	}).Result()
+}
+
+// Snapshot is a projected state and the ID of the last event folded into it.
+// Store it somewhere and pass it back to Rebuild to skip replaying history.
+type Snapshot[S any] struct {
+	State  S
+	LastID string // "" = no events applied yet
+}
+
+// Rebuild folds the aggregate's events into state with apply, starting after
+// from.LastID (pass a zero Snapshot to replay everything). Returns the new
+// state together with the last event ID, ready to be saved as a snapshot.
+func Rebuild[S any](ctx context.Context, store *EventStore, aggregateID string, from Snapshot[S], apply func(state *S, event Event)) (Snapshot[S], error) {
+	snap := from
+	key := store.streamKey(aggregateID)
+
+	for {
+		start := "-"
+		if snap.LastID != "" {
+			start = "(" + snap.LastID // Exclusive: skip the event we already have
+		}
+
+		entries, err := store.client.XRangeN(ctx, key, start, "+", replayPageSize).Result()
+		if err != nil {
+			return snap, err
+		}
+
+		for _, entry := range entries {
+			event := Event{ID: entry.ID, Data: make(map[string]string, len(entry.Values))}
+			for k, v := range entry.Values {
+				if k == typeField {
+					event.Type, _ = v.(string)
+				} else {
+					event.Data[k], _ = v.(string)
+				}
+			}
+			apply(&snap.State, event)
+			snap.LastID = entry.ID
+		}
+
+		if len(entries) < replayPageSize {
+			return snap, nil
+		}
+	}
+}
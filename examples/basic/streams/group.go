@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// consumeBatch is both how many messages Consume reads at a time and the
+// size of its channel buffer
+const consumeBatch = 10
+
+// ConsumerGroup is one consumer's handle on a stream consumer group
+type ConsumerGroup struct {
+	client   *redis.Client
+	stream   string
+	group    string
+	consumer string
+}
+
+// NewConsumerGroup creates the group (and the stream, if missing) so that it
+// starts from the beginning of the stream. If the group already exists it is
+// left as is, so every consumer can call this on startup.
+func NewConsumerGroup(ctx context.Context, client *redis.Client, stream, group, consumer string) (*ConsumerGroup, error) {
+	err := client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !isBusyGroup(err) {
+		return nil, err
+	}
+
+	return &ConsumerGroup{
+		client:   client,
+		stream:   stream,
+		group:    group,
+		consumer: consumer,
+	}, nil
+}
+
+// isBusyGroup reports whether err is Redis's BUSYGROUP reply (group already
+// exists). Server errors start with an error code, so match on that rather
+// than on the full message text.
+func isBusyGroup(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "BUSYGROUP")
+}
+
+// Read returns up to count new messages for this consumer, blocking up to
+// block for them to arrive (0 = don't block). No messages is not an error.
+func (g *ConsumerGroup) Read(ctx context.Context, count int64, block time.Duration) ([]redis.XMessage, error) {
+	if block == 0 {
+		block = -1 // go-redis: negative omits BLOCK, 0 would block forever
+	}
+
+	streams, err := g.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    g.group,
+		Consumer: g.consumer,
+		Streams:  []string{g.stream, ">"}, // ">" means new messages only
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, s := range streams {
+		messages = append(messages, s.Messages...)
+	}
+	return messages, nil
+}
+
+// Ack marks messages as processed, removing them from the pending list
+func (g *ConsumerGroup) Ack(ctx context.Context, ids ...string) error {
+	return g.client.XAck(ctx, g.stream, g.group, ids...).Err()
+}
+
+// Reclaim takes over up to count messages that other consumers of the group
+// read but haven't acked for at least minIdle (e.g. because they crashed),
+// and returns them for this consumer to process and Ack.
+func (g *ConsumerGroup) Reclaim(ctx context.Context, minIdle time.Duration, count int64) ([]redis.XMessage, error) {
+	var claimed []redis.XMessage
+	start := "0-0"
+	for int64(len(claimed)) < count {
+		// XAUTOCLAIM scans the pending list from start and claims idle entries
+		messages, next, err := g.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   g.stream,
+			Group:    g.group,
+			Consumer: g.consumer,
+			MinIdle:  minIdle,
+			Start:    start,
+			Count:    count - int64(len(claimed)),
+		}).Result()
+		if isUnknownCommand(err) {
+			return g.reclaimPending(ctx, minIdle, count)
+		}
+		if err != nil {
+			return claimed, err
+		}
+
+		claimed = append(claimed, messages...)
+		if next == "0-0" {
+			break // Scanned the whole pending list
+		}
+		start = next
+	}
+	return claimed, nil
+}
+
+// reclaimPending is Reclaim for servers older than 6.2 (no XAUTOCLAIM):
+// find idle entries with XPENDING, then XCLAIM them
+func (g *ConsumerGroup) reclaimPending(ctx context.Context, minIdle time.Duration, count int64) ([]redis.XMessage, error) {
+	pending, err := g.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: g.stream,
+		Group:  g.group,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, p := range pending {
+		if p.Idle >= minIdle {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// XCLAIM re-checks the idle time, so a message acked or claimed in the
+	// meantime is skipped
+	return g.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   g.stream,
+		Group:    g.group,
+		Consumer: g.consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+}
+
+// isUnknownCommand reports whether the server didn't recognise the command
+func isUnknownCommand(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "ERR unknown command")
+}
+
+// Message is a stream message delivered by Consume
+type Message struct {
+	redis.XMessage
+	group *ConsumerGroup
+}
+
+// Ack acknowledges the message in the group it was read from
+func (m Message) Ack(ctx context.Context) error {
+	return m.group.Ack(ctx, m.ID)
+}
+
+// Consume reads new messages in a background goroutine and delivers them on
+// the returned channel, which is closed once ctx is cancelled. The goroutine
+// only reads more from Redis when there is room in the channel, so a slow
+// consumer doesn't pile up unacked messages in its pending list.
+func (g *ConsumerGroup) Consume(ctx context.Context) (<-chan Message, error) {
+	// Fail fast if the stream or group doesn't exist
+	if err := g.client.XInfoGroups(ctx, g.stream).Err(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan Message, consumeBatch)
+	go func() {
+		defer close(out)
+
+		for ctx.Err() == nil {
+			// Short block so cancellation is noticed promptly while idle
+			messages, err := g.Read(ctx, consumeBatch, time.Second)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Consumer %s read error: %v", g.consumer, err)
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+
+			for _, msg := range messages {
+				select {
+				case out <- Message{XMessage: msg, group: g}:
+				case <-ctx.Done():
+					// Undelivered messages stay pending for Reclaim
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
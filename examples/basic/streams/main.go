@@ -78,6 +78,9 @@ func main() {
 	// Demo 5: Real-world Event Sourcing
 	demo5EventSourcing(client)
 
+	// Demo 6: Retries and Dead-Letter Stream
+	demo6RetryDeadLetter(client)
+
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║          Streams = Redis's answer to Kafka! 🎉              ║")
@@ -193,6 +196,30 @@ func demo2ReadingStreams(client *redis.Client) {
 		}
 	}
 	fmt.Println()
+
+	// Typed stream - structs in, structs out (stored as one JSON field)
+	fmt.Println("TypedStream (JSON payloads):")
+	orders := NewTypedStream[OrderPlaced](client, "orders:typed")
+	client.Del(ctx, "orders:typed")
+	orders.Add(ctx, OrderPlaced{OrderID: "ORD-100", Customer: "alice", Total: 59.98, Items: []string{"book", "pen"}})
+	orders.Add(ctx, OrderPlaced{OrderID: "ORD-101", Customer: "bob", Total: 12.50, Items: []string{"mug"}})
+
+	placed, err := orders.Read(ctx, "0", 10, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, msg := range placed {
+		fmt.Printf("  ID: %s, %s ordered %v ($%.2f)\n", msg.ID, msg.Event.Customer, msg.Event.Items, msg.Event.Total)
+	}
+	fmt.Println()
+}
+
+// OrderPlaced is an example typed stream event
+type OrderPlaced struct {
+	OrderID  string   `json:"order_id"`
+	Customer string   `json:"customer"`
+	Total    float64  `json:"total"`
+	Items    []string `json:"items"`
 }
 
 // Demo 3: Consumer Groups (Like Kafka!)
@@ -218,9 +245,13 @@ func demo3ConsumerGroups(client *redis.Client) {
 	}
 	fmt.Println("✓ Added 6 events to stream")
 
-	// Create consumer group (start from beginning with "0")
-	err := client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
-	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+	// Create consumer group (start from beginning); safe to call if it already exists
+	consumer1, err := NewConsumerGroup(ctx, client, stream, group, "consumer-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+	consumer2, err := NewConsumerGroup(ctx, client, stream, group, "consumer-2")
+	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Printf("✓ Created consumer group: %s\n", group)
@@ -232,47 +263,65 @@ func demo3ConsumerGroups(client *redis.Client) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		consumeMessages(client, stream, group, "consumer-1", 3)
+		consumeMessages(consumer1, 3)
 	}()
 
 	// Consumer 2
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		consumeMessages(client, stream, group, "consumer-2", 3)
+		consumeMessages(consumer2, 3)
 	}()
 
 	wg.Wait()
 	fmt.Println()
 
+	// Channel-based consumer: messages arrive on a Go channel
+	for i := 7; i <= 11; i++ {
+		client.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{"event_id": fmt.Sprintf("evt-%d", i)},
+		})
+	}
+	consumer3, err := NewConsumerGroup(ctx, client, stream, group, "consumer-3")
+	if err != nil {
+		log.Fatal(err)
+	}
+	consumeCtx, cancel := context.WithCancel(ctx)
+	messages, err := consumer3.Consume(consumeCtx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for received := 0; received < 5; received++ {
+		msg := <-messages
+		fmt.Printf("  [consumer-3 via channel] Processing: %s\n", msg.Values["event_id"])
+		msg.Ack(ctx)
+	}
+	cancel()
+	for range messages {
+		// Drain until the reader goroutine closes the channel
+	}
+	fmt.Println()
+
 	fmt.Println("  Key insight: Each message was delivered to ONLY ONE consumer!")
 	fmt.Println("  This is load balancing, just like Kafka consumer groups.")
 	fmt.Println()
 }
 
-func consumeMessages(client *redis.Client, stream, group, consumer string, count int) {
+func consumeMessages(cg *ConsumerGroup, count int) {
 	ctx := context.Background()
 
 	for i := 0; i < count; i++ {
 		// XREADGROUP - Read as part of consumer group
-		streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    group,
-			Consumer: consumer,
-			Streams:  []string{stream, ">"}, // ">" means new messages only
-			Count:    1,
-			Block:    time.Second,
-		}).Result()
-
+		messages, err := cg.Read(ctx, 1, time.Second)
 		if err != nil {
 			continue
 		}
 
-		for _, s := range streams {
-			for _, msg := range s.Messages {
-				fmt.Printf("  [%s] Processing: %s\n", consumer, msg.Values["event_id"])
-				// Acknowledge the message
-				client.XAck(ctx, stream, group, msg.ID)
-			}
+		for _, msg := range messages {
+			fmt.Printf("  [%s] Processing: %s\n", cg.consumer, msg.Values["event_id"])
+			// Acknowledge the message
+			cg.Ack(ctx, msg.ID)
 		}
 	}
 }
@@ -306,43 +355,58 @@ func demo4Acknowledgment(client *redis.Client) {
 	})
 
 	// Create consumer group
-	client.XGroupCreateMkStream(ctx, stream, group, "0")
+	worker, err := NewConsumerGroup(ctx, client, stream, group, "worker-1")
+	if err != nil {
+		log.Fatal(err)
+	}
 	fmt.Println("✓ Created 3 orders and consumer group")
 
 	// Read messages but DON'T acknowledge yet
-	streams, _ := client.XReadGroup(ctx, &redis.XReadGroupArgs{
-		Group:    group,
-		Consumer: "worker-1",
-		Streams:  []string{stream, ">"},
-		Count:    3,
-	}).Result()
+	messages, _ := worker.Read(ctx, 3, 0)
 
 	var messageIDs []string
-	for _, s := range streams {
-		for _, msg := range s.Messages {
-			messageIDs = append(messageIDs, msg.ID)
-			fmt.Printf("✓ Read (not acked): %s - %s\n", msg.ID, msg.Values["order_id"])
-		}
+	for _, msg := range messages {
+		messageIDs = append(messageIDs, msg.ID)
+		fmt.Printf("✓ Read (not acked): %s - %s\n", msg.ID, msg.Values["order_id"])
 	}
 
 	// Check pending messages
-	pending, _ := client.XPending(ctx, stream, group).Result()
-	fmt.Printf("\n📊 Pending messages: %d\n", pending.Count)
-	fmt.Printf("📊 Consumers with pending: %v\n", pending.Consumers)
+	report, err := PendingReport(ctx, client, stream, group)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n📊 Pending messages: %d (%s … %s)\n", report.Total, report.LowestID, report.HighestID)
+	fmt.Printf("📊 Consumers with pending: %v\n", report.Consumers)
+	fmt.Printf("📊 Oldest pending idle for: %v\n", report.OldestIdle)
 
 	// Acknowledge first message only
 	if len(messageIDs) > 0 {
-		client.XAck(ctx, stream, group, messageIDs[0])
+		worker.Ack(ctx, messageIDs[0])
 		fmt.Printf("\n✓ Acknowledged: %s\n", messageIDs[0])
 	}
 
 	// Check pending again
-	pending, _ = client.XPending(ctx, stream, group).Result()
+	pending, _ := client.XPending(ctx, stream, group).Result()
 	fmt.Printf("📊 Pending messages after ack: %d\n", pending.Count)
+
+	// worker-1 "crashes" - a healthy consumer takes over its idle messages
+	time.Sleep(100 * time.Millisecond)
+	rescuer, _ := NewConsumerGroup(ctx, client, stream, group, "worker-2")
+	reclaimed, err := rescuer.Reclaim(ctx, 50*time.Millisecond, 10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println()
+	for _, msg := range reclaimed {
+		fmt.Printf("🔄 worker-2 reclaimed: %s - %s\n", msg.ID, msg.Values["order_id"])
+		rescuer.Ack(ctx, msg.ID)
+	}
+	pending, _ = client.XPending(ctx, stream, group).Result()
+	fmt.Printf("📊 Pending messages after reclaim + ack: %d\n", pending.Count)
 	fmt.Println()
 
 	fmt.Println("  Key insight: Unacknowledged messages stay in 'pending' state")
-	fmt.Println("  and can be reclaimed if a consumer crashes!")
+	fmt.Println("  and can be reclaimed (XAUTOCLAIM) if a consumer crashes!")
 	fmt.Println()
 }
 
@@ -354,68 +418,147 @@ func demo5EventSourcing(client *redis.Client) {
 	fmt.Println()
 
 	ctx := context.Background()
+	store := NewEventStore(client, "user")
 	userStream := "user:123:events"
 
 	// Clean start
 	client.Del(ctx, userStream)
 
 	// Event sourcing: Store all user events
-	events := []map[string]interface{}{
-		{"event": "user.created", "email": "alice@example.com", "name": "Alice"},
-		{"event": "user.email_verified", "verified_at": "2024-01-15T10:30:00Z"},
-		{"event": "user.profile_updated", "name": "Alice Smith", "bio": "Software Engineer"},
-		{"event": "user.subscription_started", "plan": "pro", "amount": "29.99"},
+	events := []Event{
+		{Type: "user.created", Data: map[string]string{"email": "alice@example.com", "name": "Alice"}},
+		{Type: "user.email_verified", Data: map[string]string{"verified_at": "2024-01-15T10:30:00Z"}},
+		{Type: "user.profile_updated", Data: map[string]string{"name": "Alice Smith", "bio": "Software Engineer"}},
+		{Type: "user.subscription_started", Data: map[string]string{"plan": "pro", "amount": "29.99"}},
 	}
 
 	fmt.Println("Adding user lifecycle events:")
 	for _, event := range events {
-		id, _ := client.XAdd(ctx, &redis.XAddArgs{
-			Stream: userStream,
-			Values: event,
-		}).Result()
-		fmt.Printf("  ✓ %s: %s\n", id, event["event"])
+		id, _ := store.Append(ctx, "123", event)
+		fmt.Printf("  ✓ %s: %s\n", id, event.Type)
 	}
 	fmt.Println()
 
-	// Rebuild state from events
-	fmt.Println("Rebuilding user state from event stream:")
-	userState := make(map[string]string)
-
-	entries, _ := client.XRange(ctx, userStream, "-", "+").Result()
-	for _, entry := range entries {
-		eventType := entry.Values["event"].(string)
-		switch eventType {
+	// The projection: how each event changes the user's state
+	applyUserEvent := func(state *map[string]string, event Event) {
+		if *state == nil {
+			*state = make(map[string]string)
+		}
+		userState := *state
+		switch event.Type {
 		case "user.created":
-			userState["email"] = entry.Values["email"].(string)
-			userState["name"] = entry.Values["name"].(string)
+			userState["email"] = event.Data["email"]
+			userState["name"] = event.Data["name"]
 			userState["verified"] = "false"
 		case "user.email_verified":
 			userState["verified"] = "true"
 		case "user.profile_updated":
-			if name, ok := entry.Values["name"].(string); ok {
+			if name, ok := event.Data["name"]; ok {
 				userState["name"] = name
 			}
-			if bio, ok := entry.Values["bio"].(string); ok {
+			if bio, ok := event.Data["bio"]; ok {
 				userState["bio"] = bio
 			}
 		case "user.subscription_started":
-			userState["plan"] = entry.Values["plan"].(string)
+			userState["plan"] = event.Data["plan"]
 		}
 	}
 
+	// Rebuild state from events
+	fmt.Println("Rebuilding user state from event stream:")
+	snapshot, err := Rebuild(ctx, store, "123", Snapshot[map[string]string]{}, applyUserEvent)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	fmt.Println("  Current state:")
-	for k, v := range userState {
+	for k, v := range snapshot.State {
 		fmt.Printf("    %s: %s\n", k, v)
 	}
 	fmt.Println()
 
+	// Resume from the snapshot: only events after snapshot.LastID are replayed
+	store.Append(ctx, "123", Event{Type: "user.profile_updated", Data: map[string]string{"bio": "Staff Engineer"}})
+	replayed := 0
+	snapshot, _ = Rebuild(ctx, store, "123", snapshot, func(state *map[string]string, event Event) {
+		replayed++
+		applyUserEvent(state, event)
+	})
+	fmt.Printf("Resumed from snapshot: replayed %d new event(s), bio is now %q\n", replayed, snapshot.State["bio"])
+	fmt.Println()
+
 	// Show stream trim for retention
 	fmt.Println("Stream management:")
 	length, _ := client.XLen(ctx, userStream).Result()
 	fmt.Printf("  Stream length: %d\n", length)
-	fmt.Println("  XTRIM can be used to limit stream size:")
-	fmt.Println("    XTRIM stream MAXLEN ~ 1000  (keep ~1000 entries)")
-	fmt.Println("    XTRIM stream MINID ~ <id>   (remove entries older than ID)")
+
+	// Capped appends: XADD ... MAXLEN ~ 100
+	activityStream := "user:123:activity"
+	client.Del(ctx, activityStream)
+	producer := NewProducer(client, 100)
+	for i := 0; i < 1000; i++ {
+		producer.Append(ctx, activityStream, map[string]interface{}{"page_view": i})
+	}
+	length, _ = client.XLen(ctx, activityStream).Result()
+	fmt.Printf("  Appended 1000 entries with MAXLEN ~ 100 → length: %d\n", length)
+
+	// Retention by age: XTRIM MINID ~ <now>-0 drops everything older than now
+	cutoff := fmt.Sprintf("%d-0", time.Now().UnixMilli())
+	removed, _ := producer.TrimMinID(ctx, activityStream, cutoff)
+	fmt.Printf("  TrimMinID(%s) removed %d entries\n", cutoff, removed)
+	fmt.Println()
+}
+
+// Demo 6: Retries and Dead-Letter Stream
+func demo6RetryDeadLetter(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 6: Retries and Dead-Letter Stream")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	stream := "payments"
+	dlq := "payments:dlq"
+
+	// Clean start
+	client.Del(ctx, stream, dlq)
+
+	for _, id := range []string{"PAY-001", "PAY-BAD", "PAY-003"} {
+		client.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{"payment_id": id},
+		})
+	}
+	fmt.Println("✓ Added 3 payments (PAY-BAD always fails)")
+
+	group, err := NewConsumerGroup(ctx, client, stream, "payment-processors", "worker-1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handler := func(ctx context.Context, msg redis.XMessage) error {
+		if msg.Values["payment_id"] == "PAY-BAD" {
+			fmt.Printf("  ❌ %s failed\n", msg.Values["payment_id"])
+			return fmt.Errorf("card declined")
+		}
+		fmt.Printf("  ✅ %s processed\n", msg.Values["payment_id"])
+		return nil
+	}
+
+	// Max 3 deliveries, retry after 100ms idle
+	processor := NewStreamProcessor(group, handler, 3, 100*time.Millisecond, dlq)
+	runCtx, cancel := context.WithTimeout(ctx, time.Second)
+	processor.Run(runCtx)
+	cancel()
+	fmt.Println()
+
+	dead, _ := client.XRange(ctx, dlq, "-", "+").Result()
+	for _, entry := range dead {
+		fmt.Printf("💀 Dead-lettered: %s after %s deliveries (%s)\n",
+			entry.Values["payment_id"], entry.Values["deliveries"], entry.Values["failure_reason"])
+	}
+	pending, _ := client.XPending(ctx, stream, "payment-processors").Result()
+	fmt.Printf("📊 Still pending: %d\n", pending.Count)
 	fmt.Println()
 }
 
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PendingSummary describes a consumer group's unacked messages. A large or
+// growing Total, or a big OldestIdle, points at a stuck or crashed consumer.
+type PendingSummary struct {
+	Total      int64            // Messages delivered but not acked
+	Consumers  map[string]int64 // Pending count per consumer
+	OldestIdle time.Duration    // Time since the oldest pending message was last delivered
+	LowestID   string           // Oldest pending message ("" if none)
+	HighestID  string           // Newest pending message ("" if none)
+}
+
+// PendingReport summarizes XPENDING for a stream's consumer group
+func PendingReport(ctx context.Context, client *redis.Client, stream, group string) (PendingSummary, error) {
+	summary := PendingSummary{Consumers: map[string]int64{}}
+
+	pending, err := client.XPending(ctx, stream, group).Result()
+	if err != nil {
+		return summary, err
+	}
+	summary.Total = pending.Count
+	summary.LowestID = pending.Lower
+	summary.HighestID = pending.Higher
+	for name, count := range pending.Consumers {
+		summary.Consumers[name] = count
+	}
+	if pending.Count == 0 {
+		return summary, nil
+	}
+
+	// The summary form has no idle times; look up the oldest entry itself
+	oldest, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  pending.Lower,
+		End:    pending.Lower,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return summary, err
+	}
+	if len(oldest) > 0 {
+		summary.OldestIdle = oldest[0].Idle
+	}
+	return summary, nil
+}
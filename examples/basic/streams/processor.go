@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamHandler processes one message. Returning an error leaves the message
+// pending so it is retried.
+type StreamHandler func(ctx context.Context, msg redis.XMessage) error
+
+// StreamProcessor runs a handler over a consumer group with retries and a
+// dead-letter stream. A failed message stays in the pending list; once it
+// has been idle for RetryDelay it is reclaimed and handled again. The
+// delivery count Redis keeps for it (XPENDING) tells us when to give up:
+// after MaxRetries deliveries it is copied to the dead-letter stream and acked.
+type StreamProcessor struct {
+	group      *ConsumerGroup
+	handler    StreamHandler
+	maxRetries int64
+	retryDelay time.Duration
+	dlqStream  string
+}
+
+func NewStreamProcessor(group *ConsumerGroup, handler StreamHandler, maxRetries int64, retryDelay time.Duration, dlqStream string) *StreamProcessor {
+	return &StreamProcessor{
+		group:      group,
+		handler:    handler,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		dlqStream:  dlqStream,
+	}
+}
+
+// Run processes messages until ctx is cancelled
+func (p *StreamProcessor) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Retries first, so failed messages don't starve behind new ones
+		retries, err := p.group.Reclaim(ctx, p.retryDelay, 10)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Stream processor reclaim error: %v", err)
+		}
+		p.handle(ctx, retries)
+
+		messages, err := p.group.Read(ctx, 10, p.retryDelay)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Stream processor read error: %v", err)
+			time.Sleep(p.retryDelay)
+		}
+		p.handle(ctx, messages)
+	}
+}
+
+func (p *StreamProcessor) handle(ctx context.Context, messages []redis.XMessage) {
+	for _, msg := range messages {
+		if err := p.process(ctx, msg); err != nil && ctx.Err() == nil {
+			log.Printf("Stream processor: %s: %v", msg.ID, err)
+		}
+	}
+}
+
+// process handles one message and acks it, leaves it pending for a retry,
+// or dead-letters it. Only Redis errors are returned.
+func (p *StreamProcessor) process(ctx context.Context, msg redis.XMessage) error {
+	cause := p.handler(ctx, msg)
+	if cause == nil {
+		return p.group.Ack(ctx, msg.ID)
+	}
+
+	deliveries, err := p.deliveries(ctx, msg.ID)
+	if err != nil {
+		return err
+	}
+	if deliveries < p.maxRetries {
+		log.Printf("Stream processor: %s failed (delivery %d/%d), will retry: %v", msg.ID, deliveries, p.maxRetries, cause)
+		return nil
+	}
+	return p.deadLetter(ctx, msg, deliveries, cause)
+}
+
+// deliveries returns how many times the message has been delivered
+func (p *StreamProcessor) deliveries(ctx context.Context, id string) (int64, error) {
+	pending, err := p.group.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: p.group.stream,
+		Group:  p.group.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	return pending[0].RetryCount, nil
+}
+
+// deadLetter copies the message to the dead-letter stream with the failure
+// details and acks the original, in one MULTI/EXEC so it is never lost or
+// dead-lettered twice
+func (p *StreamProcessor) deadLetter(ctx context.Context, msg redis.XMessage, deliveries int64, cause error) error {
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values["original_stream"] = p.group.stream
+	values["original_id"] = msg.ID
+	values["deliveries"] = deliveries
+	values["failure_reason"] = cause.Error()
+
+	_, err := p.group.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: p.dlqStream, Values: values})
+		pipe.XAck(ctx, p.group.stream, p.group.group, msg.ID)
+		return nil
+	})
+	if err == nil {
+		log.Printf("Stream processor: %s dead-lettered after %d deliveries: %v", msg.ID, deliveries, cause)
+	}
+	return err
+}
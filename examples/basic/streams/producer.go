@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Producer appends to streams with a length cap so they can't grow unbounded
+type Producer struct {
+	client *redis.Client
+	maxLen int64 // Approximate max entries per stream (0 = unbounded)
+}
+
+func NewProducer(client *redis.Client, maxLen int64) *Producer {
+	return &Producer{
+		client: client,
+		maxLen: maxLen,
+	}
+}
+
+// Append adds an entry with XADD ... MAXLEN ~ maxLen. The "~" lets Redis trim
+// only whole internal nodes, which is much cheaper than an exact cap; the
+// stream may briefly hold somewhat more than maxLen entries.
+func (p *Producer) Append(ctx context.Context, stream string, values map[string]interface{}) (string, error) {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+}
+
+// TrimMinID drops entries older than minID (XTRIM MINID ~), for retention by
+// age: IDs start with a millisecond timestamp, so "<unix ms>-0" means
+// "everything before that time". Returns how many entries were removed.
+func (p *Producer) TrimMinID(ctx context.Context, stream, minID string) (int64, error) {
+	return p.client.XTrimMinIDApprox(ctx, stream, minID, 0).Result()
+}
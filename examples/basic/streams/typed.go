@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// typedField is the single stream field a TypedStream stores its JSON in
+const typedField = "data"
+
+// TypedStream stores events of type T as JSON, so producers and consumers
+// work with structs instead of map[string]interface{}
+type TypedStream[T any] struct {
+	client *redis.Client
+	stream string
+}
+
+// TypedMessage is a decoded stream entry
+type TypedMessage[T any] struct {
+	ID    string
+	Event T
+}
+
+func NewTypedStream[T any](client *redis.Client, stream string) *TypedStream[T] {
+	return &TypedStream[T]{
+		client: client,
+		stream: stream,
+	}
+}
+
+// Add appends event and returns its stream ID
+func (s *TypedStream[T]) Add(ctx context.Context, event T) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{typedField: data},
+	}).Result()
+}
+
+// Read returns up to count events after lastID ("0" = from the start),
+// blocking up to block for new ones (0 = don't block). An entry that isn't a
+// valid T is reported as an error naming its ID; the events decoded before
+// it are still returned.
+func (s *TypedStream[T]) Read(ctx context.Context, lastID string, count int64, block time.Duration) ([]TypedMessage[T], error) {
+	if block == 0 {
+		block = -1 // go-redis: negative omits BLOCK, 0 would block forever
+	}
+
+	streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{s.stream, lastID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []TypedMessage[T]
+	for _, st := range streams {
+		for _, entry := range st.Messages {
+			raw, ok := entry.Values[typedField].(string)
+			if !ok {
+				return messages, fmt.Errorf("stream %s entry %s: missing %q field", s.stream, entry.ID, typedField)
+			}
+
+			var event T
+			if err := json.Unmarshal([]byte(raw), &event); err != nil {
+				return messages, fmt.Errorf("stream %s entry %s: %w", s.stream, entry.ID, err)
+			}
+			messages = append(messages, TypedMessage[T]{ID: entry.ID, Event: event})
+		}
+	}
+	return messages, nil
+}
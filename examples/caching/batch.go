@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetMany fetches keys in one MGET round trip. Only hits are in the result;
+// misses (and cached "not found" tombstones) are simply absent.
+func (c *Cache[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	hits, _, err := c.mget(ctx, keys)
+	return hits, err
+}
+
+// mget is GetMany that also returns the keys holding a tombstone
+func (c *Cache[T]) mget(ctx context.Context, keys []string) (hits map[string]T, negative map[string]bool, err error) {
+	hits = make(map[string]T, len(keys))
+	negative = make(map[string]bool)
+	if len(keys) == 0 {
+		return hits, negative, nil
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, raw := range values {
+		s, ok := raw.(string) // nil for a missing key
+		c.stats.recordRead(ok)
+		if !ok {
+			continue
+		}
+		if bytes.Equal([]byte(s), tombstone) {
+			negative[keys[i]] = true
+			continue
+		}
+		var value T
+		if err := c.cfg.codec.Unmarshal([]byte(s), &value); err != nil {
+			return nil, nil, err
+		}
+		hits[keys[i]] = value
+	}
+	return hits, negative, nil
+}
+
+// GetOrLoadMany is the batch GetOrLoad: one MGET for the cached keys, one
+// loader call for all the misses, one pipeline to back-fill them.
+// loader returns whatever it found; keys it leaves out are not found (and
+// tombstoned if WithNegativeTTL is set). Rendering a 50-item page costs 3
+// round trips instead of 50+.
+func (c *Cache[T]) GetOrLoadMany(ctx context.Context, keys []string, ttl time.Duration, loader func(missing []string) (map[string]T, error)) (map[string]T, error) {
+	result, negative, err := c.mget(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tombstoned keys are known not to exist - don't ask the loader again
+	var missing []string
+	for _, key := range keys {
+		if _, ok := result[key]; !ok && !negative[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	start := time.Now()
+	loaded, err := loader(missing)
+	c.stats.recordLoad(time.Since(start), err != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range missing {
+			value, ok := loaded[key]
+			if !ok {
+				if c.cfg.negativeTTL > 0 {
+					pipe.Set(ctx, key, tombstone, c.cfg.negativeTTL)
+				}
+				continue
+			}
+
+			data, err := c.cfg.codec.Marshal(value)
+			if err != nil {
+				return err
+			}
+			pipe.Set(ctx, key, data, c.jitteredTTL(ttl))
+			result[key] = value
+		}
+		return nil
+	})
+	return result, err
+}
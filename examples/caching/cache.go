@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// loadLockTTL bounds how long one process may hold the cross-process load lock
+	loadLockTTL = 5 * time.Second
+	// loadPollInterval is how often lock losers re-check the cache
+	loadPollInterval = 20 * time.Millisecond
+)
+
+// ErrNotFound is returned by a loader when the item doesn't exist in the
+// source of truth. With WithNegativeTTL set, GetOrLoad caches that answer.
+var ErrNotFound = errors.New("cache: not found")
+
+// tombstone marks a cached "not found". The leading NUL keeps it from
+// colliding with anything a text codec like JSON produces.
+var tombstone = []byte("\x00cache:tombstone")
+
+// Codec turns values into bytes for Redis and back
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// CacheOption configures a Cache
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	codec       Codec
+	negativeTTL time.Duration // 0 = don't cache misses
+	jitter      float64       // TTLs are randomized within ±jitter (0.1 = ±10%)
+}
+
+// WithCodec replaces the default JSON codec
+func WithCodec(codec Codec) CacheOption {
+	return func(c *cacheConfig) {
+		c.codec = codec
+	}
+}
+
+// WithNegativeTTL caches "not found" results (loader returned ErrNotFound)
+// for ttl, so repeated lookups of missing items don't all reach the DB.
+// Keep it shorter than normal TTLs: a tombstone hides items created later.
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithJitter randomizes every TTL within ±fraction of the nominal value
+// (0.1 → a 5m TTL becomes 4m30s-5m30s), so keys written together don't all
+// expire together and stampede the DB.
+func WithJitter(fraction float64) CacheOption {
+	return func(c *cacheConfig) {
+		c.jitter = fraction
+	}
+}
+
+// Cache is a typed cache-aside wrapper over Redis.
+// It hides the marshal/unmarshal boilerplate the demos write by hand:
+//
+//	products := NewCache[Product](client)
+//	products.Set(ctx, "product:1", p, 5*time.Minute)
+//	p, found, err := products.Get(ctx, "product:1")
+type Cache[T any] struct {
+	client *redis.Client
+	cfg    cacheConfig
+
+	// Collapses concurrent in-process loads of the same key into one
+	loads singleflight.Group
+
+	stats cacheCounters
+}
+
+func NewCache[T any](client *redis.Client, opts ...CacheOption) *Cache[T] {
+	cfg := cacheConfig{codec: JSONCodec{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Cache[T]{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Get returns the cached value. A cache miss is not an error:
+// it returns the zero T and false. A cached "not found" is also a miss.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	value, found, negative, err := c.lookup(ctx, key)
+	if err == nil {
+		c.stats.recordRead(found || negative)
+	}
+	return value, found, err
+}
+
+// lookup is Get that also reports whether the key holds a tombstone
+func (c *Cache[T]) lookup(ctx context.Context, key string) (value T, found, negative bool, err error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return value, false, false, nil
+	}
+	if err != nil {
+		return value, false, false, err
+	}
+
+	if bytes.Equal(data, tombstone) {
+		return value, false, true, nil
+	}
+	if err := c.cfg.codec.Unmarshal(data, &value); err != nil {
+		return value, false, false, err
+	}
+	return value, true, false, nil
+}
+
+// cached is the first step of GetOrLoad: done is true when the cache
+// answered (a value, a tombstone as ErrNotFound, or a Redis error)
+func (c *Cache[T]) cached(ctx context.Context, key string) (value T, done bool, err error) {
+	value, found, negative, err := c.lookup(ctx, key)
+	switch {
+	case err != nil:
+		return value, true, err
+	case negative:
+		return value, true, ErrNotFound
+	default:
+		return value, found, nil
+	}
+}
+
+// Set stores a value with a TTL (0 = no expiration)
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := c.cfg.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, c.jitteredTTL(ttl)).Err()
+}
+
+// jitteredTTL applies WithJitter. 0 (no expiration) stays 0, and the result
+// is never below 1ms so jitter can't turn into "no expiration" or an error.
+func (c *Cache[T]) jitteredTTL(ttl time.Duration) time.Duration {
+	if c.cfg.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	offset := (rand.Float64()*2 - 1) * c.cfg.jitter // in [-jitter, +jitter)
+	return max(time.Duration(float64(ttl)*(1+offset)), time.Millisecond)
+}
+
+// Delete removes keys from the cache (e.g. to invalidate after a DB write)
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// GetOrLoad returns the cached value, or calls loader on a miss and caches
+// the result for ttl. Stampede protection happens at two levels:
+//
+//  1. In-process: singleflight makes concurrent callers for the same key
+//     share one loader call.
+//  2. Across processes: a short Redis lock (SET NX) lets one process load
+//     while the others poll the cache for its result.
+//
+// If loader fails, nothing is cached and the error is returned. The exception
+// is ErrNotFound with WithNegativeTTL set: a tombstone is cached, and later
+// calls return ErrNotFound without calling loader until it expires.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	value, done, err := c.cached(ctx, key)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		c.stats.recordRead(done)
+	}
+	if done {
+		return value, err
+	}
+
+	result, err, _ := c.loads.Do(key, func() (any, error) {
+		return c.loadLocked(ctx, key, ttl, loader)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+// loadLocked loads a missing key while holding the cross-process lock,
+// or waits for whoever holds it to fill the cache
+func (c *Cache[T]) loadLocked(ctx context.Context, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	lockKey := "lock:" + key
+	token := fmt.Sprint(rand.Int63())
+
+	deadline := time.Now().Add(loadLockTTL)
+	for {
+		// Someone may have filled it while we waited
+		if value, done, err := c.cached(ctx, key); done {
+			return value, err
+		}
+
+		acquired, err := c.client.SetNX(ctx, lockKey, token, loadLockTTL).Result()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		if acquired || time.Now().After(deadline) {
+			// Won the lock (or the holder is taking too long) - load it ourselves
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-time.After(loadPollInterval):
+		}
+	}
+	defer c.releaseLock(ctx, lockKey, token)
+
+	start := time.Now()
+	value, err := loader()
+	c.stats.recordLoad(time.Since(start), err != nil && !errors.Is(err, ErrNotFound))
+	if errors.Is(err, ErrNotFound) && c.cfg.negativeTTL > 0 {
+		if err := c.client.Set(ctx, key, tombstone, c.cfg.negativeTTL).Err(); err != nil {
+			return value, err
+		}
+		return value, ErrNotFound
+	}
+	if err != nil {
+		return value, err
+	}
+	return value, c.Set(ctx, key, value, ttl)
+}
+
+// releaseLock deletes the lock only if we still own it
+func (c *Cache[T]) releaseLock(ctx context.Context, lockKey, token string) {
+	script := `
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("del", KEYS[1])
+		end
+		return 0
+	`
+	c.client.Eval(ctx, script, []string{lockKey}, token)
+}
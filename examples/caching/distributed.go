@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Defaults for the L1 in front of a DistributedCache (pass a non-zero
+	// l1TTL to override the TTL). The TTL can be longer than a plain
+	// TwoLevelCache's because writes evict every instance's copy.
+	distributedL1Size = 1000
+	distributedL1TTL  = 5 * time.Minute
+)
+
+// DistributedCache is a TwoLevelCache whose writes also evict the key from
+// every other instance's L1. Each write publishes "<instance id>|<key>" on
+// a pub/sub channel that all instances subscribe to.
+//
+// Pub/sub is fire-and-forget: an instance that is disconnected when the
+// message goes out keeps its stale copy until the L1 TTL lapses.
+type DistributedCache[T any] struct {
+	local      *TwoLevelCache[T]
+	client     *redis.Client
+	channel    string
+	instanceID string
+
+	sub  *redis.PubSub
+	done chan struct{}
+}
+
+// NewDistributedCache creates a cache instance and subscribes it to channel.
+// l1TTL bounds how stale an instance can be after missing an invalidation
+// message (0 = distributedL1TTL); opts configure the Redis-backed L2.
+// Call Close to stop the subscriber.
+func NewDistributedCache[T any](client *redis.Client, channel string, l1TTL time.Duration, opts ...CacheOption) (*DistributedCache[T], error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	sub := client.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed so no invalidation is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	if l1TTL <= 0 {
+		l1TTL = distributedL1TTL
+	}
+
+	c := &DistributedCache[T]{
+		local:      NewTwoLevelCache(NewCache[T](client, opts...), distributedL1Size, l1TTL),
+		client:     client,
+		channel:    channel,
+		instanceID: hex.EncodeToString(id),
+		sub:        sub,
+		done:       make(chan struct{}),
+	}
+	go c.listen()
+	return c, nil
+}
+
+// listen evicts keys other instances wrote until the subscription is closed
+func (c *DistributedCache[T]) listen() {
+	defer close(c.done)
+
+	for msg := range c.sub.Channel() {
+		sender, key, ok := strings.Cut(msg.Payload, "|")
+		if !ok || sender == c.instanceID {
+			// Our own write already evicted our L1
+			continue
+		}
+		c.local.l1.remove(key)
+	}
+}
+
+// Get checks L1, then Redis
+func (c *DistributedCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	return c.local.Get(ctx, key)
+}
+
+// GetOrLoad is TwoLevelCache.GetOrLoad
+func (c *DistributedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	return c.local.GetOrLoad(ctx, key, ttl, loader)
+}
+
+// Set writes to Redis and evicts the key from every instance's L1
+func (c *DistributedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := c.local.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return c.publish(ctx, key)
+}
+
+// Delete removes keys from Redis and from every instance's L1
+func (c *DistributedCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if err := c.local.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := c.publish(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DistributedCache[T]) publish(ctx context.Context, key string) error {
+	return c.client.Publish(ctx, c.channel, c.instanceID+"|"+key).Err()
+}
+
+// Close unsubscribes and waits for the listener to exit
+func (c *DistributedCache[T]) Close() error {
+	err := c.sub.Close()
+	<-c.done
+	if err != nil {
+		log.Printf("Distributed cache %s: closing subscriber: %v", c.instanceID, err)
+	}
+	return err
+}
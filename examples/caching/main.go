@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -118,6 +119,9 @@ func main() {
 	// Demo 5: Multi-Level Caching
 	demo5MultiLevelCaching(client)
 
+	// Demo 6: Write-Behind Pattern
+	demo6WriteBehind(client)
+
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║          Master these patterns for interviews! 🎉           ║")
@@ -133,6 +137,7 @@ func demo1CacheAside(client *redis.Client) {
 
 	ctx := context.Background()
 	db := NewSimulatedDatabase()
+	products := NewCache[Product](client)
 	productID := "prod-001"
 
 	fmt.Println("Pattern flow:")
@@ -147,11 +152,12 @@ func demo1CacheAside(client *redis.Client) {
 		cacheKey := "product:" + id
 
 		// Step 1: Check cache
-		cached, err := client.Get(ctx, cacheKey).Result()
-		if err == nil {
+		product, found, err := products.Get(ctx, cacheKey)
+		if err != nil {
+			return Product{}, err
+		}
+		if found {
 			// Cache HIT
-			var product Product
-			json.Unmarshal([]byte(cached), &product)
 			fmt.Printf("  ✓ Cache HIT for %s\n", id)
 			return product, nil
 		}
@@ -164,8 +170,7 @@ func demo1CacheAside(client *redis.Client) {
 		}
 
 		// Step 3: Store in cache (with TTL)
-		data, _ := json.Marshal(product)
-		client.Set(ctx, cacheKey, data, 5*time.Minute)
+		products.Set(ctx, cacheKey, product, 5*time.Minute)
 		fmt.Printf("  ✓ Stored in cache with 5-minute TTL\n")
 
 		return product, nil
@@ -184,6 +189,50 @@ func demo1CacheAside(client *redis.Client) {
 	fmt.Println()
 
 	fmt.Printf("Database queries: %d (only 1 despite 2 requests!)\n", db.GetQueryCount())
+	stats := products.Stats()
+	fmt.Printf("Cache stats: %d hits, %d misses (%.0f%% hit rate)\n", stats.Hits, stats.Misses, stats.HitRate()*100)
+	fmt.Println()
+
+	// Negative caching: remember "not found" too, so scrapers probing
+	// nonexistent IDs don't reach the database on every request
+	fmt.Println("Negative caching (3 lookups of a missing product):")
+	guarded := NewCache[Product](client, WithNegativeTTL(30*time.Second))
+	missingKey := "product:prod-999"
+	guarded.Delete(ctx, missingKey)
+
+	before := db.GetQueryCount()
+	for i := 0; i < 3; i++ {
+		_, err := guarded.GetOrLoad(ctx, missingKey, 5*time.Minute, func() (Product, error) {
+			product, exists := db.Get("prod-999")
+			if !exists {
+				return Product{}, ErrNotFound
+			}
+			return product, nil
+		})
+		fmt.Printf("  → %v\n", err)
+	}
+	fmt.Printf("  Database queries: %d (tombstone cached for 30s)\n", db.GetQueryCount()-before)
+	fmt.Println()
+
+	// Batch: a list page needs 3 products; prod-001 is already cached,
+	// the other two are loaded in one go instead of one query each
+	fmt.Println("Batch load (list page of 3 products):")
+	pageKeys := []string{"product:prod-001", "product:prod-002", "product:prod-003"}
+	products.Delete(ctx, "product:prod-002", "product:prod-003")
+
+	page, _ := products.GetOrLoadMany(ctx, pageKeys, 5*time.Minute, func(missing []string) (map[string]Product, error) {
+		fmt.Printf("  ✗ %d misses, loading in one batch: %v\n", len(missing), missing)
+		loaded := make(map[string]Product, len(missing))
+		for _, key := range missing {
+			if product, exists := db.Get(strings.TrimPrefix(key, "product:")); exists {
+				loaded[key] = product
+			}
+		}
+		return loaded, nil
+	})
+	for _, key := range pageKeys {
+		fmt.Printf("  → %s: %s\n", key, page[key].Name)
+	}
 	fmt.Println()
 }
 
@@ -227,6 +276,18 @@ func demo2CacheTTLRefresh(client *redis.Client) {
 	fmt.Println("  User accessed → TTL reset to 30 minutes")
 	fmt.Println("  This keeps active sessions alive!")
 	fmt.Println()
+
+	// Jittered TTL: same nominal TTL, different actual expiry per key
+	fmt.Println("Jittered TTL (5 keys, 5 min ±10%):")
+	jittered := NewCache[string](client, WithJitter(0.1))
+	for i := 1; i <= 5; i++ {
+		key := fmt.Sprintf("cache:jitter:%d", i)
+		jittered.Set(ctx, key, "value", 5*time.Minute)
+		ttl, _ := client.TTL(ctx, key).Result()
+		fmt.Printf("  %s → expires in %v\n", key, ttl)
+	}
+	fmt.Println("  Keys written together no longer expire together")
+	fmt.Println()
 }
 
 // Demo 3: Write-Through Pattern
@@ -238,6 +299,7 @@ func demo3WriteThrough(client *redis.Client) {
 
 	ctx := context.Background()
 	db := NewSimulatedDatabase()
+	products := NewCache[Product](client)
 
 	fmt.Println("Pattern flow:")
 	fmt.Println("  1. Write to database AND cache simultaneously")
@@ -253,8 +315,7 @@ func demo3WriteThrough(client *redis.Client) {
 		db.Save(product)
 
 		// Step 2: Write to cache
-		data, _ := json.Marshal(product)
-		client.Set(ctx, cacheKey, data, 5*time.Minute)
+		products.Set(ctx, cacheKey, product, 5*time.Minute)
 		fmt.Printf("  → Writing to cache: %s\n", product.Name)
 
 		return nil
@@ -272,9 +333,7 @@ func demo3WriteThrough(client *redis.Client) {
 	updateProduct(newProduct)
 
 	// Read back - will be cache hit
-	cached, _ := client.Get(ctx, "product:prod-002").Result()
-	var product Product
-	json.Unmarshal([]byte(cached), &product)
+	product, _, _ := products.Get(ctx, "product:prod-002")
 	fmt.Printf("\n  Cache contains: %s ($%.2f)\n", product.Name, product.Price)
 	fmt.Println()
 
@@ -298,51 +357,60 @@ func demo4CacheStampedePrevention(client *redis.Client) {
 	fmt.Println("Problem: Cache expires → Many requests hit DB simultaneously")
 	fmt.Println()
 
-	fmt.Println("Solution 1: Distributed Lock (SETNX)")
-	fmt.Println("─────────────────────────────────────")
+	fmt.Println("Solution 1: Single-Flight + Distributed Lock (SETNX)")
+	fmt.Println("────────────────────────────────────────────────────")
 
 	cacheKey := "product:popular"
-	lockKey := "lock:product:popular"
-
-	// Simulate getting data with lock
-	getData := func(id string) string {
-		// Try to get from cache
-		cached, err := client.Get(ctx, cacheKey).Result()
-		if err == nil {
-			return cached
-		}
-
-		// Cache miss - try to acquire lock
-		acquired, _ := client.SetNX(ctx, lockKey, "1", 5*time.Second).Result()
-		if acquired {
-			// I won the lock - fetch from DB
-			fmt.Println("  → Lock acquired, fetching from DB...")
-			time.Sleep(100 * time.Millisecond) // Simulate DB query
-			data := `{"name":"Popular Product"}`
-
-			// Store in cache
-			client.Set(ctx, cacheKey, data, 5*time.Minute)
-			client.Del(ctx, lockKey)
-			return data
-		} else {
-			// Someone else is fetching - wait and retry
-			fmt.Println("  → Lock held by another process, waiting...")
-			time.Sleep(50 * time.Millisecond)
-			cached, _ := client.Get(ctx, cacheKey).Result()
-			return cached
-		}
+	popular := NewCache[Product](client)
+	var loads int64
+
+	// Loader only runs on a miss - and only once, however many callers pile up
+	loader := func() (Product, error) {
+		atomic.AddInt64(&loads, 1)
+		fmt.Println("  → Loader running, fetching from DB...")
+		time.Sleep(100 * time.Millisecond) // Simulate DB query
+		return Product{ID: "popular", Name: "Popular Product"}, nil
 	}
 
 	client.Del(ctx, cacheKey) // Ensure cache miss
-	result := getData("popular")
-	fmt.Printf("  Result: %s\n", result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			popular.GetOrLoad(ctx, cacheKey, 5*time.Minute, loader)
+		}()
+	}
+	wg.Wait()
+
+	result, _, _ := popular.Get(ctx, cacheKey)
+	fmt.Printf("  Result: %s\n", result.Name)
+	fmt.Printf("  20 concurrent requests → %d DB load(s)\n", atomic.LoadInt64(&loads))
 	fmt.Println()
 
-	fmt.Println("Solution 2: Probabilistic Early Expiration")
-	fmt.Println("──────────────────────────────────────────")
-	fmt.Println("  Store: value + expiration_time")
-	fmt.Println("  On read: if (now + random) > expiration_time → refresh")
+	fmt.Println("Solution 2: Probabilistic Early Expiration (XFetch)")
+	fmt.Println("───────────────────────────────────────────────────")
+	fmt.Println("  Store: value + compute time (delta) + expiry")
+	fmt.Println("  On read: if now - delta*beta*ln(rand) >= expiry → refresh")
 	fmt.Println("  This staggers refreshes before actual expiration")
+
+	perKey := "product:per"
+	var refreshes int64
+	slowLoader := func() (Product, error) {
+		atomic.AddInt64(&refreshes, 1)
+		time.Sleep(50 * time.Millisecond) // Expensive recompute → bigger head start
+		return Product{ID: "per", Name: "PER Product"}, nil
+	}
+
+	client.Del(ctx, perKey)
+	popular.GetOrLoadPER(ctx, perKey, time.Second, 1.0, slowLoader) // Initial load
+	for i := 0; i < 20; i++ {
+		time.Sleep(50 * time.Millisecond)
+		popular.GetOrLoadPER(ctx, perKey, time.Second, 1.0, slowLoader)
+	}
+	fmt.Printf("  20 reads over ~2s with a 1s TTL → %d load(s), refreshed early instead of all missing at once\n",
+		atomic.LoadInt64(&refreshes))
 	fmt.Println()
 
 	fmt.Println("Solution 3: Background Refresh")
@@ -360,12 +428,12 @@ func demo5MultiLevelCaching(client *redis.Client) {
 	fmt.Println()
 
 	ctx := context.Background()
+	db := NewSimulatedDatabase()
 
-	// Simulate L1 cache (in-memory, per-server)
-	l1Cache := make(map[string]string)
-
-	// L2 cache is Redis
-	// L3 is Database
+	// L2 is Redis, shared by every server; each server has its own L1
+	redisCache := NewCache[Product](client)
+	server1 := NewTwoLevelCache(redisCache, 1000, 30*time.Second)
+	server2 := NewTwoLevelCache(redisCache, 1000, 30*time.Second)
 
 	fmt.Println("Architecture:")
 	fmt.Println("  ┌─────────────────────────────────────────────────┐")
@@ -395,47 +463,119 @@ func demo5MultiLevelCaching(client *redis.Client) {
 	fmt.Println()
 
 	// Demonstrate multi-level lookup
-	getData := func(key string) string {
-		// L1: Check in-memory cache
-		if data, ok := l1Cache[key]; ok {
-			fmt.Printf("  L1 HIT: %s\n", key)
-			return data
-		}
-		fmt.Printf("  L1 MISS: %s\n", key)
-
-		// L2: Check Redis
-		if data, err := client.Get(ctx, key).Result(); err == nil {
-			fmt.Printf("  L2 HIT: %s\n", key)
-			l1Cache[key] = data // Populate L1
-			return data
-		}
-		fmt.Printf("  L2 MISS: %s\n", key)
-
-		// L3: Database (simulated)
+	key := "product:prod-003"
+	loadFromDB := func() (Product, error) {
 		fmt.Printf("  L3 (DB): Fetching %s\n", key)
-		data := `{"source":"database"}`
-
-		// Populate L2 and L1
-		client.Set(ctx, key, data, 5*time.Minute)
-		l1Cache[key] = data
-
-		return data
+		product, _ := db.Get("prod-003")
+		return product, nil
+	}
+	getData := func(server *TwoLevelCache[Product]) {
+		before := redisCache.Stats()
+		product, _ := server.GetOrLoad(ctx, key, 5*time.Minute, loadFromDB)
+		after := redisCache.Stats()
+		switch {
+		case after.Loads > before.Loads:
+			fmt.Println("  L1 MISS, L2 MISS → loaded from DB")
+		case after.Hits > before.Hits:
+			fmt.Println("  L1 MISS, L2 HIT → promoted into L1")
+		default:
+			fmt.Println("  L1 HIT (no Redis call)")
+		}
+		fmt.Printf("  → Got: %s\n", product.Name)
 	}
 
-	key := "product:multi-level"
 	client.Del(ctx, key) // Ensure cache miss
 
 	fmt.Println("First request (all misses):")
-	getData(key)
+	getData(server1)
 	fmt.Println()
 
 	fmt.Println("Second request (L1 hit):")
-	getData(key)
+	getData(server1)
 	fmt.Println()
 
-	// Clear L1 to simulate different server
-	delete(l1Cache, key)
 	fmt.Println("Third request from different server (L1 miss, L2 hit):")
-	getData(key)
+	getData(server2)
+	fmt.Println()
+
+	fmt.Println("Write on server 1 invalidates its L1:")
+	server1.Set(ctx, key, Product{ID: "prod-003", Name: "Keyboard v2"}, 5*time.Minute)
+	getData(server1)
+	fmt.Println()
+
+	// Server 2 still holds the old value in its L1 - pub/sub fixes that
+	fmt.Println("Distributed invalidation (pub/sub):")
+	nodeA, err := NewDistributedCache[Product](client, "cache:invalidate", 0)
+	if err != nil {
+		log.Printf("  ❌ %v", err)
+		return
+	}
+	defer nodeA.Close()
+	nodeB, err := NewDistributedCache[Product](client, "cache:invalidate", 0)
+	if err != nil {
+		log.Printf("  ❌ %v", err)
+		return
+	}
+	defer nodeB.Close()
+
+	nodeB.Get(ctx, key) // Warm B's L1
+	nodeA.Set(ctx, key, Product{ID: "prod-003", Name: "Keyboard v3"}, 5*time.Minute)
+	time.Sleep(50 * time.Millisecond) // Let the invalidation arrive
+
+	product, _, _ := nodeB.Get(ctx, key)
+	fmt.Printf("  Node A wrote v3 → node B reads: %s (L1 evicted via pub/sub)\n", product.Name)
+	fmt.Println()
+}
+
+// Demo 6: Write-Behind (Write-Back) Pattern
+func demo6WriteBehind(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 6: Write-Behind (Write-Back) Pattern")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	db := NewSimulatedDatabase()
+	products := NewCache[Product](client)
+
+	fmt.Println("Pattern flow:")
+	fmt.Println("  1. Write to cache immediately")
+	fmt.Println("  2. Buffer the DB write, flush in batches in the background")
+	fmt.Println()
+
+	var dbMu sync.Mutex // SimulatedDatabase isn't safe for concurrent writes
+	persist := func(ops []WriteOp[Product]) error {
+		dbMu.Lock()
+		defer dbMu.Unlock()
+		fmt.Printf("  💾 Persisting batch of %d writes to DB\n", len(ops))
+		for _, op := range ops {
+			db.Save(op.Value)
+		}
+		return nil
+	}
+	writer := NewWriteBehindCache(products, persist, WriteBehindConfig{BatchSize: 5})
+
+	start := time.Now()
+	for i := 1; i <= 10; i++ {
+		writer.WriteBehind(ctx, fmt.Sprintf("product:wb-%d", i), Product{
+			ID:   fmt.Sprintf("wb-%d", i),
+			Name: fmt.Sprintf("Write-Behind Product %d", i),
+		}, 5*time.Minute)
+	}
+	fmt.Printf("  ✓ 10 writes acknowledged in %v (cache only)\n", time.Since(start).Round(time.Millisecond))
+
+	product, _, _ := products.Get(ctx, "product:wb-10")
+	fmt.Printf("  ✓ Cache already has: %s\n", product.Name)
+
+	// Close flushes whatever is still buffered
+	if err := writer.Close(); err != nil {
+		fmt.Printf("  ❌ %v\n", err)
+	}
+	fmt.Printf("  ✓ DB writes after flush: %d\n", atomic.LoadInt64(&db.writeCount))
+	fmt.Println()
+
+	fmt.Println("Trade-offs:")
+	fmt.Println("  ✅ Fast writes, batched DB load")
+	fmt.Println("  ❌ Buffered writes are lost if the process crashes before flushing")
 	fmt.Println()
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// perEntry is what GetOrLoadPER stores: the encoded value plus what XFetch
+// needs to decide on an early refresh
+type perEntry struct {
+	Data   []byte `json:"data"`   // Value encoded with the cache's codec
+	Delta  int64  `json:"delta"`  // How long the loader took (ms)
+	Expiry int64  `json:"expiry"` // Logical expiry (unix ms)
+}
+
+// GetOrLoadPER is GetOrLoad with probabilistic early recomputation (XFetch).
+// Each read of a live entry recomputes early with a probability that rises
+// as expiry approaches and with how slow the loader is, so one lucky caller
+// refreshes the value before it expires and nobody stampedes - no locks needed.
+//
+// beta tunes eagerness: 1.0 is the usual default, >1 refreshes earlier.
+// Keys written here hold an envelope, so read them only through GetOrLoadPER.
+func (c *Cache[T]) GetOrLoadPER(ctx context.Context, key string, ttl time.Duration, beta float64, loader func() (T, error)) (T, error) {
+	var value T
+
+	raw, err := c.client.Get(ctx, key).Bytes()
+	if err != nil && err != redis.Nil {
+		return value, err
+	}
+	if err == nil {
+		var entry perEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return value, err
+		}
+		delta := time.Duration(entry.Delta) * time.Millisecond
+		if !shouldRefreshEarly(time.Now(), delta, beta, time.UnixMilli(entry.Expiry), rand.Float64()) {
+			c.stats.recordRead(true)
+			err := c.cfg.codec.Unmarshal(entry.Data, &value)
+			return value, err
+		}
+	}
+
+	// Miss, or we drew an early refresh
+	c.stats.recordRead(false)
+	start := time.Now()
+	value, err = loader()
+	delta := time.Since(start)
+	c.stats.recordLoad(delta, err != nil)
+	if err != nil {
+		return value, err
+	}
+
+	data, err := c.cfg.codec.Marshal(value)
+	if err != nil {
+		return value, err
+	}
+	envelope, err := json.Marshal(perEntry{
+		Data:   data,
+		Delta:  delta.Milliseconds(),
+		Expiry: time.Now().Add(ttl).UnixMilli(),
+	})
+	if err != nil {
+		return value, err
+	}
+	return value, c.client.Set(ctx, key, envelope, ttl).Err()
+}
+
+// shouldRefreshEarly is the XFetch test: now - delta*beta*ln(r) >= expiry.
+// ln(r) is negative, so the left side is "now plus a random head start"
+// scaled by how expensive the recompute is.
+func shouldRefreshEarly(now time.Time, delta time.Duration, beta float64, expiry time.Time, r float64) bool {
+	// r comes from rand.Float64() in [0,1); flip it to (0,1] so ln never sees 0
+	headStart := -float64(delta) * beta * math.Log(1-r)
+	return !now.Add(time.Duration(headStart)).Before(expiry)
+}
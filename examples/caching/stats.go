@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats is a snapshot of a Cache's counters since it was created.
+// Compare Loads with Hits+Misses to see how much DB load the cache saves.
+type CacheStats struct {
+	Hits           int64         // Reads answered from Redis (including cached "not found")
+	Misses         int64         // Reads that had to go to the loader
+	Loads          int64         // Loader calls (singleflight makes this <= Misses)
+	LoadErrors     int64         // Loader calls that failed (ErrNotFound excluded)
+	AvgLoadLatency time.Duration // Mean loader duration
+}
+
+// HitRate is Hits / (Hits + Misses), or 0 before any reads
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// cacheCounters are updated lock-free from any goroutine
+type cacheCounters struct {
+	hits       atomic.Int64
+	misses     atomic.Int64
+	loads      atomic.Int64
+	loadErrors atomic.Int64
+	loadNanos  atomic.Int64
+}
+
+func (c *cacheCounters) recordRead(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *cacheCounters) recordLoad(took time.Duration, failed bool) {
+	c.loads.Add(1)
+	c.loadNanos.Add(int64(took))
+	if failed {
+		c.loadErrors.Add(1)
+	}
+}
+
+// Stats returns the cache's hit/miss/load counters
+func (c *Cache[T]) Stats() CacheStats {
+	s := CacheStats{
+		Hits:       c.stats.hits.Load(),
+		Misses:     c.stats.misses.Load(),
+		Loads:      c.stats.loads.Load(),
+		LoadErrors: c.stats.loadErrors.Load(),
+	}
+	if s.Loads > 0 {
+		s.AvgLoadLatency = time.Duration(c.stats.loadNanos.Load() / s.Loads)
+	}
+	return s
+}
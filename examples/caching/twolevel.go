@@ -0,0 +1,173 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// lruCache is a bounded in-process cache: least recently used entries are
+// evicted when full, and entries expire after ttl
+type lruCache[T any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // Front = most recently used
+	entries map[string]*list.Element
+	gen     uint64 // Bumped by every remove (see setIfGeneration)
+}
+
+type lruEntry[T any] struct {
+	key       string
+	value     T
+	expiresAt time.Time
+}
+
+func newLRUCache[T any](size int, ttl time.Duration) *lruCache[T] {
+	return &lruCache[T]{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (l *lruCache[T]) get(key string) (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var zero T
+	el, ok := l.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[T])
+	if time.Now().After(entry.expiresAt) {
+		l.removeElement(el)
+		return zero, false
+	}
+	l.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// generation returns a token that changes whenever any key is removed
+func (l *lruCache[T]) generation() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.gen
+}
+
+// setIfGeneration stores value only if nothing was removed since gen was read.
+// A reader takes gen before fetching from L2, so a value fetched before an
+// invalidation can't be promoted after it. The check is cache-wide rather than
+// per key to keep memory bounded; a skipped promotion just costs an L2 read.
+func (l *lruCache[T]) setIfGeneration(key string, value T, gen uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.gen != gen {
+		return false
+	}
+	l.setLocked(key, value)
+	return true
+}
+
+// setLocked must be called with mu held
+func (l *lruCache[T]) setLocked(key string, value T) {
+	expiresAt := time.Now().Add(l.ttl)
+	if el, ok := l.entries[key]; ok {
+		entry := el.Value.(*lruEntry[T])
+		entry.value, entry.expiresAt = value, expiresAt
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry[T]{key: key, value: value, expiresAt: expiresAt})
+	if l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+func (l *lruCache[T]) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Bump even if the key isn't cached: a reader may be about to promote it
+	l.gen++
+	for _, key := range keys {
+		if el, ok := l.entries[key]; ok {
+			l.removeElement(el)
+		}
+	}
+}
+
+// removeElement must be called with mu held
+func (l *lruCache[T]) removeElement(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.entries, el.Value.(*lruEntry[T]).key)
+}
+
+// TwoLevelCache puts a small in-process LRU (L1) in front of a Redis Cache
+// (L2). L1 hits cost no network round trip; L2 hits are promoted into L1.
+// Writes go to Redis and drop the local L1 copy. Other processes keep their
+// own L1 copy until its TTL lapses, so keep l1TTL short (see
+// NewDistributedCache for cross-process invalidation).
+type TwoLevelCache[T any] struct {
+	l1 *lruCache[T]
+	l2 *Cache[T]
+}
+
+// NewTwoLevelCache keeps up to l1Size entries in memory for at most l1TTL
+func NewTwoLevelCache[T any](l2 *Cache[T], l1Size int, l1TTL time.Duration) *TwoLevelCache[T] {
+	return &TwoLevelCache[T]{
+		l1: newLRUCache[T](l1Size, l1TTL),
+		l2: l2,
+	}
+}
+
+// Get checks L1, then Redis. A miss in both returns the zero T and false.
+func (c *TwoLevelCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	if value, ok := c.l1.get(key); ok {
+		return value, true, nil
+	}
+
+	gen := c.l1.generation()
+	value, found, err := c.l2.Get(ctx, key)
+	if err != nil || !found {
+		return value, false, err
+	}
+	c.l1.setIfGeneration(key, value, gen)
+	return value, true, nil
+}
+
+// GetOrLoad is Cache.GetOrLoad with L1 in front
+func (c *TwoLevelCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	if value, ok := c.l1.get(key); ok {
+		return value, nil
+	}
+
+	gen := c.l1.generation()
+	value, err := c.l2.GetOrLoad(ctx, key, ttl, loader)
+	if err != nil {
+		return value, err
+	}
+	c.l1.setIfGeneration(key, value, gen)
+	return value, nil
+}
+
+// Set writes to Redis and invalidates the local L1 entry.
+// L1 is cleared after the write, and the remove bumps the L1 generation, so a
+// concurrent Get that read the old value from Redis won't promote it.
+func (c *TwoLevelCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	err := c.l2.Set(ctx, key, value, ttl)
+	c.l1.remove(key)
+	return err
+}
+
+// Delete removes keys from Redis and L1
+func (c *TwoLevelCache[T]) Delete(ctx context.Context, keys ...string) error {
+	err := c.l2.Delete(ctx, keys...)
+	c.l1.remove(keys...)
+	return err
+}
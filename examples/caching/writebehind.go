@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteBehindClosed is returned by WriteBehind after Close
+var ErrWriteBehindClosed = errors.New("cache: write-behind closed")
+
+// WriteOp is one buffered DB write
+type WriteOp[T any] struct {
+	Key   string
+	Value T
+}
+
+// WriteBehindConfig tunes the background flushers. Zero fields use defaults.
+type WriteBehindConfig struct {
+	Workers       int           // Flusher goroutines; each key always goes to the same one (default 1)
+	BufferSize    int           // Pending writes, split across workers, before WriteBehind blocks (default 1000)
+	BatchSize     int           // Max ops per persist call (default 100)
+	FlushInterval time.Duration // Flush a partial batch this often (default 100ms)
+	MaxRetries    int           // Persist retries per batch before giving up (default 3)
+}
+
+// WriteBehindCache implements write-behind (write-back): writes go to Redis
+// immediately, and the DB write is buffered and persisted in batches by
+// background workers. Writes are fast, but anything still buffered is lost
+// if the process dies - Close flushes it on a clean shutdown.
+//
+// Ops are sharded to workers by key hash, so writes to one key are persisted
+// in the order they were made: two workers racing on the same key could
+// otherwise leave the DB with the older value.
+type WriteBehindCache[T any] struct {
+	cache   *Cache[T]
+	persist func([]WriteOp[T]) error
+	cfg     WriteBehindConfig
+
+	ops    []chan WriteOp[T] // One per worker
+	mu     sync.RWMutex      // Guards closed against sends on a closed ops channel
+	closed bool
+	wg     sync.WaitGroup
+
+	dropped atomic.Int64 // Ops that failed to persist after all retries
+}
+
+func NewWriteBehindCache[T any](cache *Cache[T], persist func([]WriteOp[T]) error, cfg WriteBehindConfig) *WriteBehindCache[T] {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 100 * time.Millisecond
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+
+	w := &WriteBehindCache[T]{
+		cache:   cache,
+		persist: persist,
+		cfg:     cfg,
+		ops:     make([]chan WriteOp[T], cfg.Workers),
+	}
+	for i := range w.ops {
+		w.ops[i] = make(chan WriteOp[T], max(cfg.BufferSize/cfg.Workers, 1))
+		w.wg.Add(1)
+		go func(ops <-chan WriteOp[T]) {
+			defer w.wg.Done()
+			w.flusher(ops)
+		}(w.ops[i])
+	}
+	return w
+}
+
+// WriteBehind updates Redis now and queues the DB write. Blocks if the
+// buffer is full, which slows writers down instead of dropping writes.
+// After Close it returns ErrWriteBehindClosed without touching Redis.
+func (w *WriteBehindCache[T]) WriteBehind(ctx context.Context, key string, value T, ttl time.Duration) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWriteBehindClosed
+	}
+
+	if err := w.cache.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	select {
+	case w.shard(key) <- WriteOp[T]{Key: key, Value: value}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shard returns the ops channel of the worker that owns key
+func (w *WriteBehindCache[T]) shard(key string) chan<- WriteOp[T] {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return w.ops[h.Sum32()%uint32(len(w.ops))]
+}
+
+// Close stops accepting writes, flushes everything still buffered and waits
+// for the flushers. Returns an error if some writes never made it to the DB.
+func (w *WriteBehindCache[T]) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		for _, ops := range w.ops {
+			close(ops)
+		}
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	if n := w.dropped.Load(); n > 0 {
+		return fmt.Errorf("cache: %d writes failed to persist", n)
+	}
+	return nil
+}
+
+// flusher collects ops into batches, persisting when a batch fills up or
+// FlushInterval passes. When ops is closed it flushes the rest and returns.
+func (w *WriteBehindCache[T]) flusher(ops <-chan WriteOp[T]) {
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]WriteOp[T], 0, w.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.persistWithRetry(batch)
+			batch = make([]WriteOp[T], 0, w.cfg.BatchSize)
+		}
+	}
+
+	for {
+		select {
+		case op, ok := <-ops:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, op)
+			if len(batch) >= w.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// persistWithRetry calls persist, backing off 50ms, 100ms, 200ms... between
+// attempts. A batch that still fails is logged and counted as dropped.
+func (w *WriteBehindCache[T]) persistWithRetry(batch []WriteOp[T]) {
+	backoff := 50 * time.Millisecond
+	var err error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = w.persist(batch); err == nil {
+			return
+		}
+	}
+
+	log.Printf("Write-behind: dropping %d writes after %d retries: %v", len(batch), w.cfg.MaxRetries, err)
+	w.dropped.Add(int64(len(batch)))
+}
@@ -222,6 +222,35 @@ func main() {
 	// Next request will miss cache
 	cache.GetUserProfile("user3")
 
+	fmt.Println()
+
+	// Demo 4: Hot key for writes
+	fmt.Println("📌 DEMO 4: Hot Key for Writes (Sharded Counter)")
+	fmt.Println("================================================")
+
+	likes := NewShardedCounter(rdb, 8)
+	likes.Reset(ctx, "post:viral:likes")
+
+	// 50 clients liking at once, 20 likes each
+	var wg sync.WaitGroup
+	for c := 0; c < 50; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				likes.Incr(ctx, "post:viral:likes")
+			}
+		}()
+	}
+	wg.Wait()
+
+	total, _ := likes.Count(ctx, "post:viral:likes")
+	fmt.Printf("1000 likes spread over 8 keys, Count() = %d\n", total)
+	for i := 0; i < 8; i++ {
+		n, _ := rdb.Get(ctx, likes.shardKey("post:viral:likes", i)).Int()
+		fmt.Printf("  counter:post:viral:likes:%d = %d\n", i, n)
+	}
+
 	fmt.Print("\n" + `
 ╔════════════════════════════════════════════════════════════════╗
 ║                      INTERVIEW TALKING POINTS                  ║
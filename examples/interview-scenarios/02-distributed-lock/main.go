@@ -13,12 +13,26 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// ErrLockNotHeld means the lock key is gone (expired or already released)
+	ErrLockNotHeld = errors.New("lock not held")
+	// ErrLockLost means the lock is now held by a different owner
+	ErrLockLost = errors.New("lock lost to another owner")
+)
+
 // DistributedLock implements a simple Redis-based lock
+// The lock is reentrant: it's stored as a hash {identifier: holdCount}, so the
+// same instance can Acquire it again and must Release it the same number of times.
 type DistributedLock struct {
 	client     *redis.Client
 	lockKey    string
 	identifier string // Unique ID for this lock instance (to prevent deleting others' locks)
 	expiration time.Duration
+
+	// Watchdog state (only set when acquired via AcquireWithRenewal)
+	mu          sync.Mutex
+	stopRenewal context.CancelFunc
+	renewalDone chan struct{}
 }
 
 func NewDistributedLock(client *redis.Client, lockKey string, expiration time.Duration) *DistributedLock {
@@ -31,36 +45,284 @@ func NewDistributedLock(client *redis.Client, lockKey string, expiration time.Du
 }
 
 // Acquire tries to acquire the lock. Returns true if successful.
+// Acquiring a lock this instance already holds succeeds and bumps the hold count.
 func (l *DistributedLock) Acquire(ctx context.Context) (bool, error) {
-	// SET resource_name my_random_value NX PX 30000
-	success, err := l.client.SetNX(ctx, l.lockKey, l.identifier, l.expiration).Result()
+	// Like SET resource_name my_random_value NX PX 30000, but with a hold count
+	// so the owner can re-enter (same idea as Redisson's RLock)
+	script := `
+		if redis.call("exists", KEYS[1]) == 0 or redis.call("hexists", KEYS[1], ARGV[1]) == 1 then
+			redis.call("hincrby", KEYS[1], ARGV[1], 1)
+			redis.call("pexpire", KEYS[1], ARGV[2])
+			return 1
+		end
+		return 0
+	`
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey},
+		l.identifier, l.expiration.Milliseconds()).Result()
+	if err != nil {
+		return false, err
+	}
+	return result.(int64) == 1, nil
+}
+
+// AcquireWithToken acquires the lock and returns a fencing token: a number
+// from a companion counter key that strictly increases with every acquisition.
+//
+// Why: a lock holder can pause (GC, network) long enough for its lock to
+// expire and be taken by someone else, then wake up and write stale data.
+// Pass the token along with every write; the protected resource remembers
+// the highest token it has seen and rejects writes carrying a lower one.
+func (l *DistributedLock) AcquireWithToken(ctx context.Context) (int64, bool, error) {
+	// Acquire (same rules as Acquire) and INCR the counter in one atomic step.
+	// The counter has no TTL - it must never go backwards.
+	script := `
+		if redis.call("exists", KEYS[1]) == 0 or redis.call("hexists", KEYS[1], ARGV[1]) == 1 then
+			redis.call("hincrby", KEYS[1], ARGV[1], 1)
+			redis.call("pexpire", KEYS[1], ARGV[2])
+			return redis.call("incr", KEYS[2])
+		end
+		return 0
+	`
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey, l.fencingKey()},
+		l.identifier, l.expiration.Milliseconds()).Result()
+	if err != nil {
+		return 0, false, err
+	}
+
+	token := result.(int64)
+	if token == 0 {
+		return 0, false, nil
+	}
+	return token, true, nil
+}
+
+// fencingKey is the companion counter key for fencing tokens
+func (l *DistributedLock) fencingKey() string {
+	return l.lockKey + ":fencing"
+}
+
+// AcquireBlocking keeps trying to acquire the lock until it succeeds or ctx
+// is done, backing off exponentially with jitter between attempts so
+// contending workers don't hammer Redis in lockstep.
+// On cancellation it returns false and ctx.Err().
+func (l *DistributedLock) AcquireBlocking(ctx context.Context) (bool, error) {
+	const (
+		minBackoff = 10 * time.Millisecond
+		maxBackoff = 500 * time.Millisecond
+	)
+
+	backoff := minBackoff
+	for {
+		acquired, err := l.Acquire(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return false, ctxErr
+			}
+			return false, err
+		}
+		if acquired {
+			return true, nil
+		}
+
+		// Full jitter: sleep a random duration in [0, backoff)
+		sleep := time.Duration(rand.Int63n(int64(backoff)))
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// AcquireNotified waits for the lock like AcquireBlocking, but instead of
+// polling it subscribes to the lock's release channel and retries the moment
+// the holder releases (the approach Redisson uses). If no notification comes
+// (e.g. the holder crashed and the lock expired) it retries after a fallback
+// timeout anyway. On cancellation it returns false and ctx.Err().
+func (l *DistributedLock) AcquireNotified(ctx context.Context) (bool, error) {
+	// Subscribe BEFORE the first attempt so a release between our failed
+	// attempt and the subscription can't be missed
+	sub := l.client.Subscribe(ctx, l.releaseChannel())
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return false, err
+	}
+	released := sub.Channel()
+
+	fallback := l.expiration / 4
+	for {
+		acquired, err := l.Acquire(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return false, ctxErr
+			}
+			return false, err
+		}
+		if acquired {
+			return true, nil
+		}
+
+		timer := time.NewTimer(fallback)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-released:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// releaseChannel is the pub/sub channel announcing that the lock was released
+func (l *DistributedLock) releaseChannel() string {
+	return l.lockKey + ":released"
+}
+
+// AcquireWithRenewal acquires the lock and starts a watchdog goroutine
+// that keeps extending the TTL while we hold it. This protects against
+// work that runs longer than the expiration (like Redisson's watchdog).
+// Renewal stops on Release, on ctx cancellation, or as soon as the lock is lost.
+func (l *DistributedLock) AcquireWithRenewal(ctx context.Context) (bool, error) {
+	acquired, err := l.Acquire(ctx)
+	if err != nil || !acquired {
+		return acquired, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Re-entering a lock that already has a live watchdog - one is enough
+	if l.stopRenewal != nil {
+		select {
+		case <-l.renewalDone:
+			// Previous watchdog gave up (lock was lost); start a fresh one
+			l.stopRenewal()
+		default:
+			return true, nil
+		}
+	}
+
+	renewCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	l.stopRenewal = cancel
+	l.renewalDone = done
+
+	go l.watchdog(renewCtx, done)
+	return true, nil
+}
+
+// watchdog extends the lock every TTL/3 until stopped or the lock is lost
+func (l *DistributedLock) watchdog(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	interval := l.expiration / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := l.renew(ctx)
+			if err != nil || !renewed {
+				// Lock is gone (or Redis is unreachable) - stop pretending we own it
+				return
+			}
+		}
+	}
+}
+
+// renew resets the TTL to the lock's base expiration
+func (l *DistributedLock) renew(ctx context.Context) (bool, error) {
+	return l.Extend(ctx, l.expiration)
+}
+
+// Extend resets the lock's TTL to ttl, but only if this instance still owns it.
+// Returns false if the lock was lost (expired or taken by someone else).
+// Use this to extend manually when you don't want the full watchdog.
+func (l *DistributedLock) Extend(ctx context.Context, ttl time.Duration) (bool, error) {
+	script := `
+		if redis.call("hexists", KEYS[1], ARGV[1]) == 1 then
+			return redis.call("pexpire", KEYS[1], ARGV[2])
+		else
+			return 0
+		end
+	`
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey},
+		l.identifier, ttl.Milliseconds()).Result()
 	if err != nil {
 		return false, err
 	}
-	return success, nil
+	return result.(int64) == 1, nil
+}
+
+// stopWatchdog stops the renewal goroutine (if any) and waits for it to exit
+func (l *DistributedLock) stopWatchdog() {
+	l.mu.Lock()
+	cancel, done := l.stopRenewal, l.renewalDone
+	l.stopRenewal, l.renewalDone = nil, nil
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
 }
 
 // Release releases the lock safely using a Lua script
+// For a re-entered lock this only decrements the hold count; the key is
+// deleted once the count reaches zero.
 func (l *DistributedLock) Release(ctx context.Context) error {
-	// Lua script to check if value matches before deleting
+	// Lua script to check we are the owner before touching the lock
 	// This ensures we don't delete a lock that was acquired by someone else
 	// (e.g., if our lock expired and someone else took it)
+	// A full release also publishes on the release channel to wake waiters.
+	// Returns 0 = key gone, -1 = another owner, 1 = fully released, 2 = still held (count > 0)
 	script := `
-		if redis.call("get", KEYS[1]) == ARGV[1] then
-			return redis.call("del", KEYS[1])
-		else
+		if redis.call("exists", KEYS[1]) == 0 then
 			return 0
 		end
+		if redis.call("hexists", KEYS[1], ARGV[1]) == 0 then
+			return -1
+		end
+		if redis.call("hincrby", KEYS[1], ARGV[1], -1) > 0 then
+			redis.call("pexpire", KEYS[1], ARGV[2])
+			return 2
+		end
+		redis.call("del", KEYS[1])
+		redis.call("publish", ARGV[3], "released")
+		return 1
 	`
-	result, err := l.client.Eval(ctx, script, []string{l.lockKey}, l.identifier).Result()
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey},
+		l.identifier, l.expiration.Milliseconds(), l.releaseChannel()).Result()
 	if err != nil {
 		return err
 	}
 
-	if result.(int64) == 0 {
-		return errors.New("lock lost or expired")
+	status := result.(int64)
+	if status == 2 {
+		// Still held by an outer Acquire - keep the watchdog running
+		return nil
 	}
 
+	// Fully released (or lost) - nothing left to renew
+	l.stopWatchdog()
+
+	switch status {
+	case 0:
+		return ErrLockNotHeld
+	case -1:
+		return ErrLockLost
+	}
 	return nil
 }
 
@@ -80,6 +342,10 @@ func main() {
 
 	resourceID := "critical-resource"
 
+	// Demo 1: Workers competing for one lock
+	fmt.Println("\n📌 DEMO 1: Workers Competing (AcquireBlocking)")
+	fmt.Println("===============================================")
+
 	// Simulation: Multiple workers trying to access a resource
 	var wg sync.WaitGroup
 	workers := 5
@@ -93,6 +359,194 @@ func main() {
 	}
 
 	wg.Wait()
+	fmt.Println()
+
+	// Demo 2: Watchdog keeps the lock alive past its TTL
+	fmt.Println("📌 DEMO 2: Watchdog Renewal (AcquireWithRenewal)")
+	fmt.Println("=================================================")
+
+	client.Del(ctx, "lock:demo:renewal")
+	longJob := NewDistributedLock(client, "lock:demo:renewal", time.Second)
+	if ok, err := longJob.AcquireWithRenewal(ctx); err != nil || !ok {
+		log.Fatalf("renewal demo: acquire failed: ok=%v err=%v", ok, err)
+	}
+	fmt.Println("🟢 Acquired with a 1s TTL, working for 2.5s...")
+	time.Sleep(2500 * time.Millisecond)
+
+	rival := NewDistributedLock(client, "lock:demo:renewal", time.Second)
+	rivalOK, _ := rival.Acquire(ctx)
+	pttl := client.PTTL(ctx, "lock:demo:renewal").Val()
+	fmt.Printf("   After 2.5s: TTL remaining %v, rival acquired: %v (expected false)\n",
+		pttl.Round(time.Millisecond), rivalOK)
+	if err := longJob.Release(ctx); err != nil {
+		fmt.Printf("⚠️  Release failed: %v\n", err)
+	} else {
+		fmt.Println("🔴 Released - watchdog stopped")
+	}
+	fmt.Println()
+
+	// Demo 3: Reentrancy
+	fmt.Println("📌 DEMO 3: Reentrant Acquire")
+	fmt.Println("=============================")
+
+	client.Del(ctx, "lock:demo:reentrant")
+	outer := NewDistributedLock(client, "lock:demo:reentrant", 5*time.Second)
+	first, _ := outer.Acquire(ctx)
+	second, _ := outer.Acquire(ctx)
+	fmt.Printf("   Same instance acquires twice: %v, %v\n", first, second)
+	outer.Release(ctx)
+
+	other := NewDistributedLock(client, "lock:demo:reentrant", 5*time.Second)
+	otherOK, _ := other.Acquire(ctx)
+	fmt.Printf("   After one Release, another owner acquires: %v (expected false)\n", otherOK)
+	outer.Release(ctx)
+	otherOK, _ = other.Acquire(ctx)
+	fmt.Printf("   After the second Release, another owner acquires: %v\n", otherOK)
+	other.Release(ctx)
+	fmt.Println()
+
+	// Demo 4: Fencing tokens
+	fmt.Println("📌 DEMO 4: Fencing Tokens (AcquireWithToken)")
+	fmt.Println("=============================================")
+
+	client.Del(ctx, "lock:demo:fenced", "lock:demo:fenced:fencing")
+	for i := 1; i <= 3; i++ {
+		holder := NewDistributedLock(client, "lock:demo:fenced", 5*time.Second)
+		token, ok, err := holder.AcquireWithToken(ctx)
+		if err != nil || !ok {
+			fmt.Printf("❌ Client %d failed to acquire: ok=%v err=%v\n", i, ok, err)
+			continue
+		}
+		fmt.Printf("   Client %d acquired with token %d\n", i, token)
+		holder.Release(ctx)
+	}
+	fmt.Println("   Tokens only go up - storage rejects writes with a token lower than the last one it saw")
+	fmt.Println()
+
+	// Demo 5: Typed release errors
+	fmt.Println("📌 DEMO 5: Release After Expiry vs After Theft")
+	fmt.Println("===============================================")
+
+	client.Del(ctx, "lock:demo:expired", "lock:demo:stolen")
+
+	expired := NewDistributedLock(client, "lock:demo:expired", 200*time.Millisecond)
+	expired.Acquire(ctx)
+	time.Sleep(300 * time.Millisecond)
+	err := expired.Release(ctx)
+	fmt.Printf("   Lock expired, nobody took it:      %v (ErrLockNotHeld: %v)\n", err, errors.Is(err, ErrLockNotHeld))
+
+	slow := NewDistributedLock(client, "lock:demo:stolen", 200*time.Millisecond)
+	slow.Acquire(ctx)
+	time.Sleep(300 * time.Millisecond)
+	thief := NewDistributedLock(client, "lock:demo:stolen", 5*time.Second)
+	thief.Acquire(ctx)
+	err = slow.Release(ctx)
+	fmt.Printf("   Lock expired, someone else has it: %v (ErrLockLost: %v)\n", err, errors.Is(err, ErrLockLost))
+	thief.Release(ctx)
+	fmt.Println()
+
+	// Demo 6: Pub/sub wake-up instead of polling
+	fmt.Println("📌 DEMO 6: Release Notification (AcquireNotified)")
+	fmt.Println("==================================================")
+
+	client.Del(ctx, "lock:demo:notified")
+	holder := NewDistributedLock(client, "lock:demo:notified", 10*time.Second)
+	holder.Acquire(ctx)
+
+	waiter := NewDistributedLock(client, "lock:demo:notified", 10*time.Second)
+	acquiredAt := make(chan time.Time, 1)
+	go func() {
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if ok, err := waiter.AcquireNotified(waitCtx); err != nil || !ok {
+			fmt.Printf("❌ Waiter failed: ok=%v err=%v\n", ok, err)
+			close(acquiredAt)
+			return
+		}
+		acquiredAt <- time.Now()
+	}()
+
+	time.Sleep(300 * time.Millisecond) // let the waiter subscribe
+	releasedAt := time.Now()
+	holder.Release(ctx)
+	if at, ok := <-acquiredAt; ok {
+		fmt.Printf("   Waiter acquired %v after release (fallback poll would be %v)\n",
+			at.Sub(releasedAt).Round(time.Millisecond), 10*time.Second/4)
+		waiter.Release(ctx)
+	}
+	fmt.Println()
+
+	// Demo 7: Readers block a writer
+	fmt.Println("📌 DEMO 7: Readers/Writer Lock (RWLock)")
+	fmt.Println("========================================")
+
+	client.Del(ctx, "lock:demo:rw", "lock:demo:rw:writer_pending")
+	reader1 := NewRWLock(client, "lock:demo:rw", 5*time.Second)
+	reader2 := NewRWLock(client, "lock:demo:rw", 5*time.Second)
+	reader1.RLock(ctx)
+	reader2.RLock(ctx)
+	fmt.Println("   Two readers hold the lock")
+
+	writer := NewRWLock(client, "lock:demo:rw", 5*time.Second)
+	writerStart := time.Now()
+	writerDone := make(chan time.Duration, 1)
+	go func() {
+		if err := writer.Lock(ctx); err != nil {
+			fmt.Printf("❌ Writer failed: %v\n", err)
+		}
+		writerDone <- time.Since(writerStart)
+	}()
+
+	time.Sleep(500 * time.Millisecond)
+	reader1.RUnlock(ctx)
+	reader2.RUnlock(ctx)
+	fmt.Println("   Both readers released after 500ms")
+	fmt.Printf("   Writer waited %v for the readers to drain\n", (<-writerDone).Round(time.Millisecond))
+	writer.Unlock(ctx)
+	fmt.Println()
+
+	// Demo 8: Manual extension
+	fmt.Println("📌 DEMO 8: Extending a Lock (Extend)")
+	fmt.Println("=====================================")
+
+	client.Del(ctx, "lock:demo:extend")
+	extendable := NewDistributedLock(client, "lock:demo:extend", time.Second)
+	extendable.Acquire(ctx)
+	extended, _ := extendable.Extend(ctx, 10*time.Second)
+	fmt.Printf("   Extend while held:     %v (TTL now %v)\n",
+		extended, client.PTTL(ctx, "lock:demo:extend").Val().Round(time.Second))
+	extendable.Release(ctx)
+	extended, _ = extendable.Extend(ctx, 10*time.Second)
+	fmt.Printf("   Extend after release:  %v\n", extended)
+	fmt.Println()
+
+	// Demo 9: RedLock across independent nodes
+	fmt.Println("📌 DEMO 9: RedLock With One Node Down")
+	fmt.Println("======================================")
+
+	// Separate logical DBs stand in for independent Redis nodes here;
+	// the third node points at a port nothing listens on
+	nodes := []*redis.Client{
+		redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1}),
+		redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 2}),
+		redis.NewClient(&redis.Options{Addr: "localhost:6390", MaxRetries: -1}),
+	}
+	for _, node := range nodes {
+		defer node.Close()
+		node.Del(ctx, "lock:demo:redlock")
+	}
+
+	redlock := NewRedLock(nodes, "lock:demo:redlock", 5*time.Second)
+	ok, err := redlock.Acquire(ctx)
+	if err != nil {
+		fmt.Printf("❌ RedLock error: %v\n", err)
+	}
+	fmt.Printf("   2 of 3 nodes reachable, acquired: %v (quorum %d), validity %v\n",
+		ok, len(nodes)/2+1, redlock.Validity().Round(time.Millisecond))
+	if ok {
+		redlock.Release(ctx)
+	}
+
 	fmt.Println("\n✅ Simulation complete")
 }
 
@@ -100,37 +554,32 @@ func worker(ctx context.Context, client *redis.Client, id int, resourceID string
 	// Create a lock instance for this worker
 	lock := NewDistributedLock(client, "lock:"+resourceID, 2*time.Second)
 
-	retries := 5
-	for i := 0; i < retries; i++ {
-		// Try to acquire lock
-		acquired, err := lock.Acquire(ctx)
-		if err != nil {
-			log.Printf("Worker %d error: %v", id, err)
-			return
-		}
+	// Give up if we can't get the lock within 3 seconds
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
 
-		if acquired {
-			fmt.Printf("🟢 Worker %d ACQUIRED lock\n", id)
-
-			// Simulate work
-			workTime := time.Duration(rand.Intn(500)+500) * time.Millisecond
-			fmt.Printf("   Worker %d processing for %v...\n", id, workTime)
-			time.Sleep(workTime)
-
-			// Release lock
-			err := lock.Release(ctx)
-			if err != nil {
-				fmt.Printf("⚠️  Worker %d failed to release: %v\n", id, err)
-			} else {
-				fmt.Printf("🔴 Worker %d RELEASED lock\n", id)
-			}
-			return
-		} else {
-			fmt.Printf("   Worker %d waiting (attempt %d/%d)...\n", id, i+1, retries)
-			// Wait before retry (jitter)
-			time.Sleep(time.Duration(rand.Intn(500)+200) * time.Millisecond)
-		}
+	fmt.Printf("   Worker %d waiting for lock...\n", id)
+	acquired, err := lock.AcquireBlocking(waitCtx)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Worker %d error: %v", id, err)
+		return
 	}
+	if !acquired {
+		fmt.Printf("❌ Worker %d gave up\n", id)
+		return
+	}
+
+	fmt.Printf("🟢 Worker %d ACQUIRED lock\n", id)
 
-	fmt.Printf("❌ Worker %d gave up\n", id)
+	// Simulate work
+	workTime := time.Duration(rand.Intn(500)+500) * time.Millisecond
+	fmt.Printf("   Worker %d processing for %v...\n", id, workTime)
+	time.Sleep(workTime)
+
+	// Release lock
+	if err := lock.Release(ctx); err != nil {
+		fmt.Printf("⚠️  Worker %d failed to release: %v\n", id, err)
+	} else {
+		fmt.Printf("🔴 Worker %d RELEASED lock\n", id)
+	}
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedLock implements the Redlock algorithm across N independent Redis nodes.
+// The lock is held if a majority (N/2+1) of nodes granted it quickly enough
+// that some validity time is left. Losing a minority of nodes is tolerated.
+type RedLock struct {
+	clients     []*redis.Client
+	lockKey     string
+	identifier  string // Same random value on every node
+	expiration  time.Duration
+	nodeTimeout time.Duration // Max time to wait on one node (keep << expiration)
+	driftFactor float64       // Allowance for clock drift between nodes
+
+	validUntil time.Time
+}
+
+func NewRedLock(clients []*redis.Client, lockKey string, expiration time.Duration) *RedLock {
+	return &RedLock{
+		clients:     clients,
+		lockKey:     lockKey,
+		identifier:  uuid.New().String(),
+		expiration:  expiration,
+		nodeTimeout: 50 * time.Millisecond,
+		driftFactor: 0.01,
+	}
+}
+
+// Acquire tries to take the lock on a majority of nodes.
+// If the majority isn't reached (or took too long), any partial
+// acquisitions are released so other clients aren't blocked.
+func (l *RedLock) Acquire(ctx context.Context) (bool, error) {
+	start := time.Now()
+
+	acquired := 0
+	for _, client := range l.clients {
+		if l.acquireNode(ctx, client) {
+			acquired++
+		}
+	}
+
+	// Validity = TTL - time spent acquiring - clock drift allowance
+	elapsed := time.Since(start)
+	drift := time.Duration(float64(l.expiration)*l.driftFactor) + 2*time.Millisecond
+	validity := l.expiration - elapsed - drift
+
+	if acquired >= l.quorum() && validity > 0 {
+		l.validUntil = start.Add(l.expiration - drift)
+		return true, nil
+	}
+
+	// Failed: undo whatever we did get
+	l.releaseAll(ctx)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// acquireNode runs SET NX PX on one node, treating errors as "not acquired"
+func (l *RedLock) acquireNode(ctx context.Context, client *redis.Client) bool {
+	nodeCtx, cancel := context.WithTimeout(ctx, l.nodeTimeout)
+	defer cancel()
+
+	ok, err := client.SetNX(nodeCtx, l.lockKey, l.identifier, l.expiration).Result()
+	return err == nil && ok
+}
+
+// Validity returns how much longer the lock is guaranteed to be held.
+// Do not start work that can't finish within this window.
+func (l *RedLock) Validity() time.Duration {
+	remaining := time.Until(l.validUntil)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Release releases the lock on every node (not just the ones that granted it -
+// a node may have set the key even though its reply timed out). It only fails
+// if fewer than a majority of nodes could be reached; the rest expire by TTL.
+func (l *RedLock) Release(ctx context.Context) error {
+	l.validUntil = time.Time{}
+	return l.releaseAll(ctx)
+}
+
+// releaseAll runs compare-and-delete on every node. It ignores ctx's
+// cancellation: Acquire calls it to clean up precisely when ctx has been
+// cancelled or timed out, and the partial locks should still go.
+func (l *RedLock) releaseAll(ctx context.Context) error {
+	// Plain compare-and-delete on a string value. Unlike DistributedLock this
+	// lock is not reentrant, so there is no hold count to decrement.
+	script := `
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("del", KEYS[1])
+		else
+			return 0
+		end
+	`
+
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, client := range l.clients {
+		nodeCtx, cancel := context.WithTimeout(ctx, l.nodeTimeout)
+		err := client.Eval(nodeCtx, script, []string{l.lockKey}, l.identifier).Err()
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(l.clients)-len(errs) >= l.quorum() {
+		return nil
+	}
+	return fmt.Errorf("released on %d of %d nodes, need %d: %w",
+		len(l.clients)-len(errs), len(l.clients), l.quorum(), errors.Join(errs...))
+}
+
+// quorum is the number of nodes needed for a majority
+func (l *RedLock) quorum() int {
+	return len(l.clients)/2 + 1
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RWLock is a Redis-backed readers/writer lock: many readers OR one writer.
+//
+// State lives in a hash {readers: total, reader:<identifier>: count,
+// writer: identifier} plus a separate "writer pending" key. Each instance's
+// read holds are counted under its own field, so an RUnlock from an instance
+// that holds no read lock can't release somebody else's. A waiting writer sets the pending key so new readers
+// back off - otherwise a steady stream of readers could starve writers.
+//
+// Simplification: readers share one TTL on the hash rather than being tracked
+// individually, so a crashed reader holds its slot until the TTL expires.
+type RWLock struct {
+	client     *redis.Client
+	lockKey    string
+	identifier string
+	expiration time.Duration
+	retryDelay time.Duration
+}
+
+func NewRWLock(client *redis.Client, lockKey string, expiration time.Duration) *RWLock {
+	return &RWLock{
+		client:     client,
+		lockKey:    lockKey,
+		identifier: uuid.New().String(),
+		expiration: expiration,
+		retryDelay: 20 * time.Millisecond,
+	}
+}
+
+// RLock blocks until a shared (read) lock is acquired or ctx is done
+func (l *RWLock) RLock(ctx context.Context) error {
+	// Readers may enter only if there is no writer and no writer waiting
+	script := `
+		if redis.call("hexists", KEYS[1], "writer") == 1 or redis.call("exists", KEYS[2]) == 1 then
+			return 0
+		end
+		redis.call("hincrby", KEYS[1], "reader:" .. ARGV[2], 1)
+		redis.call("hincrby", KEYS[1], "readers", 1)
+		redis.call("pexpire", KEYS[1], ARGV[1])
+		return 1
+	`
+	return l.retry(ctx, func() (bool, error) {
+		result, err := l.client.Eval(ctx, script, []string{l.lockKey, l.pendingKey()},
+			l.expiration.Milliseconds(), l.identifier).Result()
+		if err != nil {
+			return false, err
+		}
+		return result.(int64) == 1, nil
+	})
+}
+
+// RUnlock releases one of this instance's shared (read) locks.
+// Returns ErrLockNotHeld if this instance holds none (or they expired).
+func (l *RWLock) RUnlock(ctx context.Context) error {
+	script := `
+		local field = "reader:" .. ARGV[1]
+		local held = tonumber(redis.call("hget", KEYS[1], field) or "0")
+		if held <= 0 then
+			return 0
+		end
+		if held == 1 then
+			redis.call("hdel", KEYS[1], field)
+		else
+			redis.call("hincrby", KEYS[1], field, -1)
+		end
+		if redis.call("hincrby", KEYS[1], "readers", -1) <= 0 then
+			redis.call("hdel", KEYS[1], "readers")
+			if redis.call("hlen", KEYS[1]) == 0 then
+				redis.call("del", KEYS[1])
+			end
+		end
+		return 1
+	`
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey}, l.identifier).Result()
+	if err != nil {
+		return err
+	}
+	if result.(int64) == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Lock blocks until the exclusive (write) lock is acquired or ctx is done.
+// While waiting it marks a writer as pending so no new readers get in.
+func (l *RWLock) Lock(ctx context.Context) error {
+	// 1. Claim the pending slot (unless another writer already has it)
+	// 2. Take the lock once readers have drained and no writer holds it
+	script := `
+		local pending = redis.call("get", KEYS[2])
+		if pending and pending ~= ARGV[1] then
+			return 0
+		end
+		redis.call("set", KEYS[2], ARGV[1], "PX", ARGV[2])
+
+		local readers = tonumber(redis.call("hget", KEYS[1], "readers") or "0")
+		if readers > 0 or redis.call("hexists", KEYS[1], "writer") == 1 then
+			return 0
+		end
+
+		redis.call("hset", KEYS[1], "writer", ARGV[1])
+		redis.call("pexpire", KEYS[1], ARGV[2])
+		redis.call("del", KEYS[2])
+		return 1
+	`
+	err := l.retry(ctx, func() (bool, error) {
+		result, err := l.client.Eval(ctx, script, []string{l.lockKey, l.pendingKey()},
+			l.identifier, l.expiration.Milliseconds()).Result()
+		if err != nil {
+			return false, err
+		}
+		return result.(int64) == 1, nil
+	})
+	if err != nil {
+		// Gave up - don't leave readers blocked behind our pending flag
+		l.clearPending(context.WithoutCancel(ctx))
+	}
+	return err
+}
+
+// Unlock releases the exclusive (write) lock
+func (l *RWLock) Unlock(ctx context.Context) error {
+	script := `
+		if redis.call("exists", KEYS[1]) == 0 then
+			return 0
+		end
+		if redis.call("hget", KEYS[1], "writer") ~= ARGV[1] then
+			return -1
+		end
+		redis.call("del", KEYS[1])
+		return 1
+	`
+	result, err := l.client.Eval(ctx, script, []string{l.lockKey}, l.identifier).Result()
+	if err != nil {
+		return err
+	}
+
+	switch result.(int64) {
+	case 0:
+		return ErrLockNotHeld
+	case -1:
+		return ErrLockLost
+	}
+	return nil
+}
+
+// retry calls try until it succeeds, fails, or ctx is done
+func (l *RWLock) retry(ctx context.Context, try func() (bool, error)) error {
+	for {
+		ok, err := try()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(l.retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// clearPending removes our writer-pending flag (only if it's ours)
+func (l *RWLock) clearPending(ctx context.Context) {
+	script := `
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("del", KEYS[1])
+		end
+		return 0
+	`
+	l.client.Eval(ctx, script, []string{l.pendingKey()}, l.identifier)
+}
+
+// pendingKey marks that a writer is waiting
+func (l *RWLock) pendingKey() string {
+	return l.lockKey + ":writer_pending"
+}
@@ -21,22 +21,32 @@ type Player struct {
 
 // Leaderboard manages game rankings using Redis Sorted Sets
 type Leaderboard struct {
-	redis      *redis.Client
-	boardName  string
-	maxPlayers int // Keep only top N players
+	redis          *redis.Client
+	boardName      string
+	maxPlayers     int    // Keep only top N players
+	changesChannel string // Publish RankChange events here (see realtime.go)
 }
 
-func NewLeaderboard(redisClient *redis.Client, boardName string, maxPlayers int) *Leaderboard {
-	return &Leaderboard{
+func NewLeaderboard(redisClient *redis.Client, boardName string, maxPlayers int, opts ...LeaderboardOption) *Leaderboard {
+	lb := &Leaderboard{
 		redis:      redisClient,
 		boardName:  boardName,
 		maxPlayers: maxPlayers,
 	}
+	for _, opt := range opts {
+		opt(lb)
+	}
+	return lb
 }
 
 // UpdateScore adds or updates a player's score
 // INTERVIEW NOTE: O(log N) time complexity
 func (lb *Leaderboard) UpdateScore(playerID string, score int) error {
+	if lb.changesChannel != "" {
+		_, err := lb.updateAndPublish("set", playerID, score)
+		return err
+	}
+
 	// ZADD is O(log N) - very efficient even with millions of players
 	return lb.redis.ZAdd(ctx, lb.boardName, redis.Z{
 		Score:  float64(score),
@@ -47,6 +57,10 @@ func (lb *Leaderboard) UpdateScore(playerID string, score int) error {
 // IncrementScore increases a player's score (common in games)
 // INTERVIEW NOTE: Atomic operation, thread-safe
 func (lb *Leaderboard) IncrementScore(playerID string, increment int) (int, error) {
+	if lb.changesChannel != "" {
+		return lb.updateAndPublish("incr", playerID, increment)
+	}
+
 	newScore, err := lb.redis.ZIncrBy(ctx, lb.boardName, float64(increment), playerID).Result()
 	if err != nil {
 		return 0, err
@@ -279,6 +293,36 @@ func main() {
 		fmt.Printf("  %d. %s - %d points\n", i+1, p.ID, p.Score)
 	}
 
+	fmt.Println()
+
+	// Demo 6: Live Updates over Pub/Sub
+	fmt.Println("📌 DEMO 6: Live Updates (Pub/Sub)")
+	fmt.Println("==================================")
+
+	live := NewLeaderboard(rdb, "game:leaderboard:live", 10, WithChangeEvents("game:leaderboard:changes"))
+	rdb.Del(ctx, "game:leaderboard:live")
+
+	subCtx, stopUI := context.WithCancel(ctx)
+	changes, err := live.SubscribeChanges(subCtx)
+	if err != nil {
+		log.Fatal("Cannot subscribe to changes:", err)
+	}
+
+	fmt.Println("UI subscribed; game servers update scores...")
+	live.UpdateScore("player1", 500)
+	live.UpdateScore("player2", 700)
+	live.IncrementScore("player1", 400) // Alice overtakes Bob
+
+	for i := 0; i < 3; i++ {
+		select {
+		case c := <-changes:
+			fmt.Printf("  📡 %s now has %d points (rank #%d)\n", c.Player, c.Score, c.Rank)
+		case <-time.After(2 * time.Second):
+			fmt.Println("  (no event received)")
+		}
+	}
+	stopUI()
+
 	fmt.Print("\n" + `
 ╔════════════════════════════════════════════════════════════════╗
 ║                      INTERVIEW TALKING POINTS                  ║
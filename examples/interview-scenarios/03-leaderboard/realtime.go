@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderboardOption configures a Leaderboard
+type LeaderboardOption func(*Leaderboard)
+
+// WithChangeEvents makes every UpdateScore/IncrementScore publish a
+// RankChange to channel, so UIs can live-update instead of polling
+func WithChangeEvents(channel string) LeaderboardOption {
+	return func(lb *Leaderboard) {
+		lb.changesChannel = channel
+	}
+}
+
+// RankChange is published after a player's score changes
+type RankChange struct {
+	Player string `json:"player"`
+	Score  int    `json:"score"`
+	Rank   int    `json:"rank"` // 1-based, like GetPlayerRank
+}
+
+// updateAndPublishScript applies the score change, reads the new rank and
+// publishes both in one atomic step, so the event always matches the board
+// INTERVIEW PATTERN: computing rank and publishing client-side after ZADD
+// would race with other updates and could publish a stale rank
+const updateAndPublishScript = `
+local score
+if ARGV[1] == "incr" then
+	score = redis.call("ZINCRBY", KEYS[1], ARGV[2], ARGV[3])
+else
+	redis.call("ZADD", KEYS[1], ARGV[2], ARGV[3])
+	score = ARGV[2]
+end
+local rank = redis.call("ZREVRANK", KEYS[1], ARGV[3])
+redis.call("PUBLISH", ARGV[4], cjson.encode({
+	player = ARGV[3],
+	score = tonumber(score),
+	rank = rank + 1,
+}))
+return score
+`
+
+// updateAndPublish runs ZADD ("set") or ZINCRBY ("incr") and publishes the
+// resulting RankChange, returning the new score
+func (lb *Leaderboard) updateAndPublish(mode, playerID string, value int) (int, error) {
+	score, err := lb.redis.Eval(ctx, updateAndPublishScript, []string{lb.boardName},
+		mode, value, playerID, lb.changesChannel).Int()
+	if err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// SubscribeChanges delivers the board's RankChange events until ctx is
+// cancelled, then closes the channel. The subscription is confirmed before
+// it returns, so no change made after the call is missed.
+// INTERVIEW NOTE: pub/sub is fire-and-forget - a UI that disconnects misses
+// events, so it should re-fetch GetTopPlayers when it reconnects
+func (lb *Leaderboard) SubscribeChanges(ctx context.Context) (<-chan RankChange, error) {
+	if lb.changesChannel == "" {
+		return nil, errors.New("leaderboard: change events not enabled (use WithChangeEvents)")
+	}
+
+	sub := lb.redis.Subscribe(ctx, lb.changesChannel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	out := make(chan RankChange)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			var msg *redis.Message
+			select {
+			case msg = <-messages:
+			case <-ctx.Done():
+				return
+			}
+
+			var change RankChange
+			if err := json.Unmarshal([]byte(msg.Payload), &change); err != nil {
+				log.Printf("leaderboard: bad change event %q: %v", msg.Payload, err)
+				continue
+			}
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BucketedWindowCounter approximates a sliding-window count with bounded memory
+// INTERVIEW PATTERN: "The sorted-set limiter stores every timestamp - can you do better?"
+//
+// The window is split into fixed sub-buckets, each a field in one hash:
+//
+//	bucket_counter:{key}  →  { <bucket id>: <count>, ... }
+//
+// Memory is at most `buckets` fields per key no matter the traffic. The summed
+// buckets start on a bucket boundary, so the count can miss up to one bucket's
+// worth of the oldest requests; more buckets means more accuracy.
+type BucketedWindowCounter struct {
+	redis    redis.UniversalClient
+	window   time.Duration
+	buckets  int
+	bucketMs int64
+}
+
+// NewBucketedWindowCounter splits a windowSecs window into buckets sub-buckets.
+// Each bucket must span at least 1ms, since bucket ids are whole milliseconds.
+func NewBucketedWindowCounter(redisClient redis.UniversalClient, windowSecs int, buckets int) (*BucketedWindowCounter, error) {
+	if windowSecs <= 0 {
+		return nil, fmt.Errorf("bucketed window: windowSecs must be positive, got %d", windowSecs)
+	}
+	window := time.Duration(windowSecs) * time.Second
+	if buckets <= 0 || window.Milliseconds()/int64(buckets) < 1 {
+		return nil, fmt.Errorf("bucketed window: buckets must be between 1 and %d for a %v window, got %d",
+			window.Milliseconds(), window, buckets)
+	}
+
+	return &BucketedWindowCounter{
+		redis:    redisClient,
+		window:   window,
+		buckets:  buckets,
+		bucketMs: window.Milliseconds() / int64(buckets),
+	}, nil
+}
+
+func (c *BucketedWindowCounter) key(key string) string {
+	return limiterKey("bucket_counter", key)
+}
+
+// currentBucket returns the id of the bucket that contains now
+func (c *BucketedWindowCounter) currentBucket() int64 {
+	return time.Now().UnixMilli() / c.bucketMs
+}
+
+// Incr records one event and returns the approximate count in the window
+func (c *BucketedWindowCounter) Incr(key string) (int64, error) {
+	// Lua script so increment, pruning and summing are one atomic step
+	luaScript := `
+		local key = KEYS[1]
+		local current = tonumber(ARGV[1])
+		local buckets = tonumber(ARGV[2])
+		local ttl_ms = tonumber(ARGV[3])
+
+		redis.call('HINCRBY', key, current, 1)
+
+		-- Drop buckets that slid out of the window, sum the rest
+		local oldest = current - buckets + 1
+		local fields = redis.call('HGETALL', key)
+		local total = 0
+		for i = 1, #fields, 2 do
+			if tonumber(fields[i]) < oldest then
+				redis.call('HDEL', key, fields[i])
+			else
+				total = total + tonumber(fields[i + 1])
+			end
+		end
+
+		redis.call('PEXPIRE', key, ttl_ms)
+		return total
+	`
+
+	return c.redis.Eval(ctx, luaScript, []string{c.key(key)},
+		c.currentBucket(), c.buckets, c.window.Milliseconds()+c.bucketMs).Int64()
+}
+
+// Count returns the approximate number of events in the window without recording one
+func (c *BucketedWindowCounter) Count(key string) (int64, error) {
+	fields, err := c.redis.HGetAll(ctx, c.key(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	oldest := c.currentBucket() - int64(c.buckets) + 1
+	var total int64
+	for field, value := range fields {
+		bucket, _ := strconv.ParseInt(field, 10, 64)
+		if bucket >= oldest {
+			count, _ := strconv.ParseInt(value, 10, 64)
+			total += count
+		}
+	}
+	return total, nil
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,15 +14,37 @@ import (
 
 var ctx = context.Background()
 
+// FailMode decides what a limiter answers when Redis itself is failing
+// INTERVIEW PATTERN: "What happens when Redis goes down?"
+type FailMode int
+
+const (
+	// FailClosed rejects requests when Redis errors (protects the backend)
+	FailClosed FailMode = iota
+	// FailOpen allows requests when Redis errors (protects the user experience)
+	FailOpen
+)
+
+// onError returns the limiter decision for a Redis error.
+// The error is always passed through so callers can log it.
+func (m FailMode) onError(err error) (bool, int, error) {
+	return m == FailOpen, 0, err
+}
+
+// ErrInvalidCost is returned for a request cost below 1. Zero or negative
+// costs would pass for free or refund budget.
+var ErrInvalidCost = errors.New("rate limiter: cost must be at least 1")
+
 // FixedWindowRateLimiter implements fixed-window rate limiting
 // INTERVIEW PATTERN: Most common and simple
 type FixedWindowRateLimiter struct {
-	redis      *redis.Client
+	redis      redis.UniversalClient
 	limit      int
 	windowSecs int
+	failMode   FailMode
 }
 
-func NewFixedWindowRateLimiter(redisClient *redis.Client, limit int, windowSecs int) *FixedWindowRateLimiter {
+func NewFixedWindowRateLimiter(redisClient redis.UniversalClient, limit int, windowSecs int) *FixedWindowRateLimiter {
 	return &FixedWindowRateLimiter{
 		redis:      redisClient,
 		limit:      limit,
@@ -27,38 +52,80 @@ func NewFixedWindowRateLimiter(redisClient *redis.Client, limit int, windowSecs
 	}
 }
 
-// CheckRateLimit returns true if request is allowed
-func (rl *FixedWindowRateLimiter) CheckRateLimit(userID string) (bool, int, error) {
-	// Key format: rate_limit:{userID}:{currentWindow}
-	// Window is determined by current time divided by window size
+// SetFailMode configures the decision returned when Redis is unavailable
+func (rl *FixedWindowRateLimiter) SetFailMode(mode FailMode) {
+	rl.failMode = mode
+}
+
+// key returns the counter key for the user's current window
+func (rl *FixedWindowRateLimiter) key(userID string) string {
+	// Key format: rate_limit:{<userID>}:<currentWindow>
+	// Window is determined by current time divided by window size.
+	// The braces are a cluster hash tag (see cluster.go).
 	currentWindow := time.Now().Unix() / int64(rl.windowSecs)
-	key := fmt.Sprintf("rate_limit:%s:%d", userID, currentWindow)
+	return fmt.Sprintf("%s:%d", limiterKey("rate_limit", userID), currentWindow)
+}
 
-	// Increment counter atomically
-	count, err := rl.redis.Incr(ctx, key).Result()
-	if err != nil {
-		return false, 0, err
+// CheckRateLimit returns true if request is allowed
+// cost is how many hits this request counts as (1 for a normal request,
+// more for expensive operations). A request that would push the counter
+// over the limit is rejected without consuming anything.
+func (rl *FixedWindowRateLimiter) CheckRateLimit(userID string, cost int) (bool, int, error) {
+	if cost < 1 {
+		return false, 0, ErrInvalidCost
 	}
 
-	// Set expiration on first request in this window
-	if count == 1 {
-		rl.redis.Expire(ctx, key, time.Duration(rl.windowSecs)*time.Second)
+	key := rl.key(userID)
+
+	// Check and increment atomically so a rejected request never
+	// partially consumes the budget
+	luaScript := `
+		local key = KEYS[1]
+		local limit = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2])
+		local cost = tonumber(ARGV[3])
+
+		local current = tonumber(redis.call('GET', key) or '0')
+		if current + cost > limit then
+			return {0, current}  -- Not allowed
+		end
+
+		local count = redis.call('INCRBY', key, cost)
+		-- Set expiration on first request in this window
+		if count == cost then
+			redis.call('EXPIRE', key, window)
+		end
+		return {1, count}  -- Allowed
+	`
+
+	result, err := rl.redis.Eval(ctx, luaScript, []string{key},
+		rl.limit, rl.windowSecs, cost).Result()
+	if err != nil {
+		return rl.failMode.onError(err)
 	}
 
-	// Check if under limit
-	allowed := count <= int64(rl.limit)
-	return allowed, int(count), nil
+	resultSlice := result.([]interface{})
+	allowed := resultSlice[0].(int64) == 1
+	count := int(resultSlice[1].(int64))
+
+	return allowed, count, nil
+}
+
+// Reset clears the user's counter for the current window
+func (rl *FixedWindowRateLimiter) Reset(userID string) error {
+	return rl.redis.Del(ctx, rl.key(userID)).Err()
 }
 
 // SlidingWindowRateLimiter implements sliding-window rate limiting
 // INTERVIEW PATTERN: More accurate but complex
 type SlidingWindowRateLimiter struct {
-	redis      *redis.Client
+	redis      redis.UniversalClient
 	limit      int
 	windowSecs int
+	failMode   FailMode
 }
 
-func NewSlidingWindowRateLimiter(redisClient *redis.Client, limit int, windowSecs int) *SlidingWindowRateLimiter {
+func NewSlidingWindowRateLimiter(redisClient redis.UniversalClient, limit int, windowSecs int) *SlidingWindowRateLimiter {
 	return &SlidingWindowRateLimiter{
 		redis:      redisClient,
 		limit:      limit,
@@ -66,50 +133,87 @@ func NewSlidingWindowRateLimiter(redisClient *redis.Client, limit int, windowSec
 	}
 }
 
+// SetFailMode configures the decision returned when Redis is unavailable
+func (rl *SlidingWindowRateLimiter) SetFailMode(mode FailMode) {
+	rl.failMode = mode
+}
+
+// key returns the sorted set holding the user's request timestamps
+func (rl *SlidingWindowRateLimiter) key(userID string) string {
+	return limiterKey("rate_limit_sliding", userID)
+}
+
 // CheckRateLimit uses sorted sets for sliding window
-func (rl *SlidingWindowRateLimiter) CheckRateLimit(userID string) (bool, int, error) {
-	key := fmt.Sprintf("rate_limit_sliding:%s", userID)
-	now := time.Now().Unix()
-	windowStart := now - int64(rl.windowSecs)
+// cost is how many entries this request adds to the window. A request
+// that would exceed the limit is rejected without adding any entries.
+func (rl *SlidingWindowRateLimiter) CheckRateLimit(userID string, cost int) (bool, int, error) {
+	if cost < 1 {
+		return false, 0, ErrInvalidCost
+	}
 
-	pipe := rl.redis.Pipeline()
+	key := rl.key(userID)
+	now := time.Now()
+	windowStart := now.Add(-time.Duration(rl.windowSecs) * time.Second)
 
-	// Remove old entries outside the window
-	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprint(windowStart))
+	// Lua script so the trim, count and add happen as one atomic step
+	luaScript := `
+		local key = KEYS[1]
+		local limit = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2])
+		local now = tonumber(ARGV[3])
+		local window_start = tonumber(ARGV[4])
+		local cost = tonumber(ARGV[5])
+		local member_prefix = ARGV[6]
 
-	// Count entries in current window
-	countCmd := pipe.ZCard(ctx, key)
+		-- Remove old entries outside the window
+		redis.call('ZREMRANGEBYSCORE', key, '-inf', window_start)
 
-	// Add current request with timestamp as score
-	pipe.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now),
-		Member: fmt.Sprintf("%d", now),
-	})
+		-- Count entries in current window
+		local count = redis.call('ZCARD', key)
+		if count + cost > limit then
+			return {0, count}  -- Not allowed
+		end
+
+		-- Add one entry per unit of cost, timestamp as score
+		for i = 1, cost do
+			redis.call('ZADD', key, now, member_prefix .. '-' .. i)
+		end
 
-	// Set expiration
-	pipe.Expire(ctx, key, time.Duration(rl.windowSecs+1)*time.Second)
+		-- Set expiration
+		redis.call('EXPIRE', key, window + 1)
+		return {1, count + cost}  -- Allowed
+	`
 
-	// Execute pipeline
-	_, err := pipe.Exec(ctx)
+	result, err := rl.redis.Eval(ctx, luaScript, []string{key},
+		rl.limit, rl.windowSecs, now.UnixMilli(), windowStart.UnixMilli(), cost,
+		fmt.Sprintf("%d", now.UnixNano())).Result()
 	if err != nil {
-		return false, 0, err
+		return rl.failMode.onError(err)
 	}
 
-	count := countCmd.Val()
-	allowed := count < int64(rl.limit)
-	return allowed, int(count + 1), nil
+	resultSlice := result.([]interface{})
+	allowed := resultSlice[0].(int64) == 1
+	count := int(resultSlice[1].(int64))
+
+	return allowed, count, nil
+}
+
+// Reset clears all of the user's timestamps in the window
+func (rl *SlidingWindowRateLimiter) Reset(userID string) error {
+	return rl.redis.Del(ctx, rl.key(userID)).Err()
 }
 
 // TokenBucketRateLimiter implements token bucket algorithm
 // INTERVIEW PATTERN: Advanced - mention if asked for sophistication
 type TokenBucketRateLimiter struct {
-	redis      *redis.Client
+	redis      redis.UniversalClient
 	capacity   int // Max tokens
 	refillRate int // Tokens per second
 	refillTime time.Duration
+	failMode   FailMode
 }
 
-func NewTokenBucketRateLimiter(redisClient *redis.Client, capacity int, refillRate int) *TokenBucketRateLimiter {
+func NewTokenBucketRateLimiter(redisClient redis.UniversalClient, capacity int, refillRate int) *TokenBucketRateLimiter {
 	return &TokenBucketRateLimiter{
 		redis:      redisClient,
 		capacity:   capacity,
@@ -118,8 +222,23 @@ func NewTokenBucketRateLimiter(redisClient *redis.Client, capacity int, refillRa
 	}
 }
 
-// CheckRateLimit consumes tokens from bucket
-func (rl *TokenBucketRateLimiter) CheckRateLimit(userID string) (bool, int, error) {
+// SetFailMode configures the decision returned when Redis is unavailable
+func (rl *TokenBucketRateLimiter) SetFailMode(mode FailMode) {
+	rl.failMode = mode
+}
+
+// key returns the hash holding the user's bucket
+func (rl *TokenBucketRateLimiter) key(userID string) string {
+	return limiterKey("rate_limit_bucket", userID)
+}
+
+// CheckRateLimit consumes cost tokens from bucket
+// If fewer than cost tokens are left, nothing is consumed.
+func (rl *TokenBucketRateLimiter) CheckRateLimit(userID string, cost int) (bool, int, error) {
+	if cost < 1 {
+		return false, 0, ErrInvalidCost
+	}
+
 	// Implementation using Lua script for atomic operations
 	luaScript := `
 		local key = KEYS[1]
@@ -155,13 +274,13 @@ func (rl *TokenBucketRateLimiter) CheckRateLimit(userID string) (bool, int, erro
 		end
 	`
 
-	key := fmt.Sprintf("rate_limit_bucket:%s", userID)
+	key := rl.key(userID)
 	now := time.Now().Unix()
 
 	result, err := rl.redis.Eval(ctx, luaScript, []string{key},
-		rl.capacity, rl.refillRate, now, 1).Result()
+		rl.capacity, rl.refillRate, now, cost).Result()
 	if err != nil {
-		return false, 0, err
+		return rl.failMode.onError(err)
 	}
 
 	resultSlice := result.([]interface{})
@@ -171,6 +290,11 @@ func (rl *TokenBucketRateLimiter) CheckRateLimit(userID string) (bool, int, erro
 	return allowed, tokens, nil
 }
 
+// Reset deletes the user's bucket; the next check starts at full capacity
+func (rl *TokenBucketRateLimiter) Reset(userID string) error {
+	return rl.redis.Del(ctx, rl.key(userID)).Err()
+}
+
 func main() {
 	fmt.Println("=== Redis Rate Limiting Patterns ===")
 
@@ -191,7 +315,7 @@ func main() {
 	fixedWindow := NewFixedWindowRateLimiter(rdb, 5, 10)
 
 	for i := 1; i <= 7; i++ {
-		allowed, count, _ := fixedWindow.CheckRateLimit("user123")
+		allowed, count, _ := fixedWindow.CheckRateLimit("user123", 1)
 		status := "✅ ALLOWED"
 		if !allowed {
 			status = "❌ RATE LIMITED"
@@ -200,6 +324,13 @@ func main() {
 		time.Sleep(500 * time.Millisecond)
 	}
 
+	// Admin lifts the limit
+	if err := fixedWindow.Reset("user123"); err != nil {
+		log.Printf("Reset failed: %v", err)
+	}
+	allowed, count, _ := fixedWindow.CheckRateLimit("user123", 1)
+	fmt.Printf("After Reset: allowed=%v (count: %d/5)\n", allowed, count)
+
 	fmt.Println()
 
 	// Demo 2: Sliding-Window Rate Limiter
@@ -210,7 +341,7 @@ func main() {
 	slidingWindow := NewSlidingWindowRateLimiter(rdb, 3, 5)
 
 	for i := 1; i <= 5; i++ {
-		allowed, count, _ := slidingWindow.CheckRateLimit("user456")
+		allowed, count, _ := slidingWindow.CheckRateLimit("user456", 1)
 		status := "✅ ALLOWED"
 		if !allowed {
 			status = "❌ RATE LIMITED"
@@ -229,7 +360,7 @@ func main() {
 	tokenBucket := NewTokenBucketRateLimiter(rdb, 10, 2)
 
 	for i := 1; i <= 6; i++ {
-		allowed, tokens, _ := tokenBucket.CheckRateLimit("user789")
+		allowed, tokens, _ := tokenBucket.CheckRateLimit("user789", 1)
 		status := "✅ ALLOWED"
 		if !allowed {
 			status = "❌ NO TOKENS"
@@ -238,6 +369,134 @@ func main() {
 		time.Sleep(1 * time.Second)
 	}
 
+	fmt.Println()
+
+	// Demo 4: Weighted requests
+	fmt.Println("📌 DEMO 4: Weighted (Variable-Cost) Requests")
+	fmt.Println("============================================")
+	fmt.Println("Capacity: 5 tokens, Refill: 1 token/sec, each export costs 3")
+
+	weighted := NewTokenBucketRateLimiter(rdb, 5, 1)
+
+	for i := 1; i <= 3; i++ {
+		allowed, tokens, _ := weighted.CheckRateLimit("user999", 3)
+		status := "✅ ALLOWED"
+		if !allowed {
+			status = "❌ NOT ENOUGH TOKENS (nothing consumed)"
+		}
+		fmt.Printf("Export %d: %s (tokens remaining: %d)\n", i, status, tokens)
+	}
+
+	// Boundary: a cost-3 request with exactly 3 tokens left must pass.
+	// Refill is disabled here so second boundaries can't add a token.
+	exactFit := NewTokenBucketRateLimiter(rdb, 5, 0)
+	exactFit.Reset("user998")
+	_, tokens, _ := exactFit.CheckRateLimit("user998", 2)
+	fmt.Printf("Small export (cost 2): tokens remaining: %d\n", tokens)
+	allowed, tokens, _ = exactFit.CheckRateLimit("user998", 3)
+	fmt.Printf("Export with exactly 3 tokens: allowed=%v (tokens remaining: %d)\n", allowed, tokens)
+
+	fmt.Println()
+
+	// Demo 5: Redis is down
+	fmt.Println("📌 DEMO 5: Fail-Open vs Fail-Closed")
+	fmt.Println("====================================")
+	fmt.Println("Pointing a limiter at a Redis that isn't running")
+
+	deadRedis := redis.NewClient(&redis.Options{
+		Addr:        "localhost:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	defer deadRedis.Close()
+
+	for _, mode := range []FailMode{FailClosed, FailOpen} {
+		limiter := NewFixedWindowRateLimiter(deadRedis, 5, 10)
+		limiter.SetFailMode(mode)
+
+		allowed, _, err := limiter.CheckRateLimit("user000", 1)
+		name := "FailClosed"
+		if mode == FailOpen {
+			name = "FailOpen"
+		}
+		fmt.Printf("%s: allowed=%v (error logged: %v)\n", name, allowed, err)
+	}
+
+	fmt.Println()
+
+	// Demo 6: Bucketed sliding window
+	fmt.Println("📌 DEMO 6: Bucketed Sliding Window (Bounded Memory)")
+	fmt.Println("===================================================")
+	fmt.Println("Window: 2 seconds split into 10 buckets, compared with the exact sorted set")
+
+	bucketed, err := NewBucketedWindowCounter(rdb, 2, 10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	exact := NewSlidingWindowRateLimiter(rdb, 1_000_000, 2)
+	rdb.Del(ctx, bucketed.key("user321"), exact.key("user321"))
+
+	for i := 1; i <= 30; i++ {
+		approx, _ := bucketed.Incr("user321")
+		_, exactCount, _ := exact.CheckRateLimit("user321", 1)
+		if i%5 == 0 {
+			fmt.Printf("After %2d requests: bucketed=%d exact=%d\n", i, approx, exactCount)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	fields := rdb.HLen(ctx, bucketed.key("user321")).Val()
+	members := rdb.ZCard(ctx, exact.key("user321")).Val()
+	fmt.Printf("Memory: %d hash fields vs %d sorted-set members\n", fields, members)
+	fmt.Println("(bucketed may miss up to one bucket's worth of the oldest requests)")
+
+	fmt.Println()
+
+	// Demo 7: Redis Cluster
+	fmt.Println("📌 DEMO 7: Cluster-Ready Keys (Hash Tags)")
+	fmt.Println("==========================================")
+
+	userKeys := []string{
+		fixedWindow.key("user:123"),
+		slidingWindow.key("user:123"),
+		tokenBucket.key("user:123"),
+		bucketed.key("user:123"),
+	}
+	for _, key := range userKeys {
+		fmt.Printf("  %-32s → slot %d\n", key, keySlot(key))
+	}
+	fmt.Printf("  %-32s → slot %d (another user, another slot)\n",
+		fixedWindow.key("user:456"), keySlot(fixedWindow.key("user:456")))
+	fmt.Println("Against a cluster, pass redis.NewClusterClient(...) to any limiter instead of rdb.")
+
+	fmt.Println()
+
+	// Demo 8: HTTP middleware
+	fmt.Println("📌 DEMO 8: HTTP Middleware (429 + Retry-After)")
+	fmt.Println("==============================================")
+	fmt.Println("Limit: 3 requests per 10 seconds per X-API-Key")
+
+	apiLimiter := NewFixedWindowRateLimiter(rdb, 3, 10)
+	apiLimiter.Reset("key-abc")
+	handler := apiLimiter.Middleware(func(r *http.Request) string {
+		return r.Header.Get("X-API-Key")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	}))
+
+	for i := 1; i <= 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/orders", nil)
+		req.Header.Set("X-API-Key", "key-abc")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		fmt.Printf("Request %d: %d %-17s X-RateLimit-Remaining=%s",
+			i, rec.Code, http.StatusText(rec.Code), rec.Header().Get("X-RateLimit-Remaining"))
+		if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "" {
+			fmt.Printf(" Retry-After=%ss", retryAfter)
+		}
+		fmt.Println()
+	}
+
 	fmt.Print("\n" + `
 ╔════════════════════════════════════════════════════════════════╗
 ║                      INTERVIEW TALKING POINTS                  ║
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// checkFunc runs one rate limit check for a client key.
+// It returns whether the request is allowed, how much budget is left,
+// and how many seconds the client should wait before retrying.
+type checkFunc func(key string) (allowed bool, remaining int, retryAfter int, err error)
+
+// rateLimitMiddleware wraps a handler with a rate limit check
+// Rejected requests get 429 Too Many Requests + Retry-After; if the limiter
+// itself failed and fails closed, 503 Service Unavailable
+func rateLimitMiddleware(keyFn func(*http.Request) string, check checkFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, remaining, retryAfter, err := check(keyFn(r))
+			if err != nil {
+				// The limiter's FailMode already decided allowed. There's no real
+				// budget to report, so skip the rate limit headers, and a
+				// FailClosed rejection is our outage (503), not the client's 429.
+				log.Printf("rate limiter error: %v", err)
+				if !allowed {
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if remaining < 0 {
+				remaining = 0
+			}
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware rate limits an http.Handler with a fixed window
+// keyFn picks the client identity (user ID, API key, IP, ...)
+func (rl *FixedWindowRateLimiter) Middleware(keyFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return rateLimitMiddleware(keyFn, func(key string) (bool, int, int, error) {
+		allowed, count, err := rl.CheckRateLimit(key, 1)
+
+		// Retry once the current window rolls over
+		window := int64(rl.windowSecs)
+		retryAfter := int(window - time.Now().Unix()%window)
+
+		return allowed, rl.limit - count, retryAfter, err
+	})
+}
+
+// Middleware rate limits an http.Handler with a sliding window
+func (rl *SlidingWindowRateLimiter) Middleware(keyFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return rateLimitMiddleware(keyFn, func(key string) (bool, int, int, error) {
+		allowed, count, err := rl.CheckRateLimit(key, 1)
+
+		// Worst case the oldest entry is brand new, so wait a full window
+		return allowed, rl.limit - count, rl.windowSecs, err
+	})
+}
+
+// Middleware rate limits an http.Handler with a token bucket
+func (rl *TokenBucketRateLimiter) Middleware(keyFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return rateLimitMiddleware(keyFn, func(key string) (bool, int, int, error) {
+		allowed, tokens, err := rl.CheckRateLimit(key, 1)
+
+		// Tokens refill every second, so the next one is at most 1s away
+		return allowed, tokens, 1, err
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ctx = context.Background()
+
+func main() {
+	fmt.Println("=== Redis Proximity Search: Find Nearby Drivers ===")
+
+	// Connect to Redis
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Fatal("Cannot connect to Redis:", err)
+	}
+
+	rdb.Del(ctx, "drivers:active")
+	drivers := NewNearbyService(rdb, "drivers:active")
+
+	// Demo 1: Drivers report their locations
+	fmt.Println("📌 DEMO 1: Drivers Report Locations (GEOADD)")
+	fmt.Println("=============================================")
+
+	locations := []struct {
+		id       string
+		lon, lat float64
+	}{
+		{"driver:1", -122.4194, 37.7749}, // Civic Center
+		{"driver:2", -122.4094, 37.7849}, // Union Square
+		{"driver:3", -122.4294, 37.7649}, // Mission Dolores
+		{"driver:4", -122.3894, 37.7949}, // Embarcadero
+		{"driver:5", -122.2711, 37.8044}, // Oakland
+	}
+	for _, l := range locations {
+		if err := drivers.AddEntity(l.id, l.lon, l.lat); err != nil {
+			log.Printf("AddEntity failed: %v", err)
+		}
+		fmt.Printf("%s at (%.4f, %.4f)\n", l.id, l.lon, l.lat)
+	}
+
+	fmt.Println()
+
+	// Demo 2: Rider requests a ride
+	fmt.Println("📌 DEMO 2: Rider Requests a Ride (GEOSEARCH)")
+	fmt.Println("=============================================")
+	riderLon, riderLat := -122.4150, 37.7750
+	fmt.Printf("Rider at (%.4f, %.4f), searching 3km, nearest 3\n", riderLon, riderLat)
+
+	printNearby := func() {
+		nearby, err := drivers.Nearby(riderLon, riderLat, 3000, 3)
+		if err != nil {
+			log.Printf("Nearby failed: %v", err)
+			return
+		}
+		for i, r := range nearby {
+			fmt.Printf("  %d. %s - %.0fm away\n", i+1, r.ID, r.DistanceMeters)
+		}
+	}
+	printNearby()
+
+	fmt.Println()
+
+	// Demo 3: Drivers move and go offline
+	fmt.Println("📌 DEMO 3: Driver Moves, Another Goes Offline")
+	fmt.Println("==============================================")
+	drivers.AddEntity("driver:5", -122.4160, 37.7755) // Oakland driver crosses the bridge
+	drivers.RemoveEntity("driver:1")                  // Civic Center driver goes offline
+	fmt.Println("driver:5 moved next to the rider, driver:1 went offline")
+	printNearby()
+
+	fmt.Print("\n" + `
+╔════════════════════════════════════════════════════════════════╗
+║                      INTERVIEW TALKING POINTS                  ║
+╠════════════════════════════════════════════════════════════════╣
+║                                                                ║
+║ 1️⃣  DATA STRUCTURE                                             ║
+║    "Geo index = sorted set scored by 52-bit geohash"           ║
+║    - GEOADD: O(log N) per location update                      ║
+║    - GEOSEARCH: O(N + log M), results sorted by distance       ║
+║                                                                ║
+║ 2️⃣  STALE LOCATIONS                                            ║
+║    - Members can't have their own TTL                          ║
+║    - Remove on logout, or keep a last-seen sorted set and      ║
+║      ZREM drivers whose last update is too old                 ║
+║                                                                ║
+║ 3️⃣  SCALING                                                    ║
+║    - Shard by city/region: drivers:sf, drivers:nyc             ║
+║    - Separate indexes for available vs busy drivers            ║
+║                                                                ║
+╚════════════════════════════════════════════════════════════════╝
+`)
+}
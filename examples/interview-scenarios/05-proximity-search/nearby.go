@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/redis/go-redis/v9"
+)
+
+// NearbyResult is one entity found by a proximity query
+type NearbyResult struct {
+	ID             string
+	DistanceMeters float64
+}
+
+// NearbyService finds entities (drivers, restaurants, friends) near a point
+// INTERVIEW PATTERN: "Design Uber - find nearby drivers"
+//
+// Locations live in one geo index, which is a sorted set scored by geohash:
+//
+//	key  →  { <entity id>: <geohash of lon/lat>, ... }
+type NearbyService struct {
+	redis *redis.Client
+	key   string
+}
+
+func NewNearbyService(redisClient *redis.Client, key string) *NearbyService {
+	return &NearbyService{
+		redis: redisClient,
+		key:   key,
+	}
+}
+
+// AddEntity adds an entity or moves it to a new location
+// INTERVIEW NOTE: O(log N), cheap enough for drivers reporting every few seconds
+func (s *NearbyService) AddEntity(id string, lon, lat float64) error {
+	return s.redis.GeoAdd(ctx, s.key, &redis.GeoLocation{
+		Name:      id,
+		Longitude: lon,
+		Latitude:  lat,
+	}).Err()
+}
+
+// RemoveEntity takes an entity out of the index (e.g. driver goes offline)
+func (s *NearbyService) RemoveEntity(id string) error {
+	// A geo index is a sorted set, so ZREM removes members
+	return s.redis.ZRem(ctx, s.key, id).Err()
+}
+
+// Nearby returns up to limit entities within radiusMeters of lon/lat,
+// nearest first
+// INTERVIEW NOTE: O(N + log M) where N is entities in the searched area
+func (s *NearbyService) Nearby(lon, lat float64, radiusMeters float64, limit int) ([]NearbyResult, error) {
+	locations, err := s.redis.GeoSearchLocation(ctx, s.key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lon,
+			Latitude:   lat,
+			Radius:     radiusMeters,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      limit,
+		},
+		WithDist: true,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]NearbyResult, len(locations))
+	for i, loc := range locations {
+		results[i] = NearbyResult{ID: loc.Name, DistanceMeters: loc.Dist}
+	}
+	return results, nil
+}
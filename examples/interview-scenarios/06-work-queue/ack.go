@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// SQS-style delivery: Dequeue hides a job for a visibility timeout instead of
+// handing it to one worker's processing list. The consumer must Ack (done) or
+// Nack (retry now); if it does neither before the deadline, the reaper makes
+// the job visible again. Keys (prefix "jobs"):
+//
+//	jobs:inflight            ZSET   job IDs scored by visibility deadline (ms)
+//	jobs:inflight:data       HASH   job ID -> job JSON, for redelivery
+//	jobs:inflight:receipts   HASH   job ID -> receipt of the current delivery
+//
+// Each delivery gets a fresh receipt, and Ack/Nack only act if it still
+// matches. Once a job is redelivered, the first consumer's late Ack or Nack
+// is refused instead of clearing the new delivery.
+
+func (q *Queue) inflightKey() string         { return q.prefix + ":inflight" }
+func (q *Queue) inflightDataKey() string     { return q.prefix + ":inflight:data" }
+func (q *Queue) inflightReceiptsKey() string { return q.prefix + ":inflight:receipts" }
+
+// Delivery is one hand-out of a job by Dequeue. Pass it back to Ack or Nack.
+type Delivery struct {
+	Job
+	Receipt string // Identifies this delivery; a redelivery gets a new one
+}
+
+// newReceipt returns a random delivery receipt
+func newReceipt() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Dequeue takes the next job and hides it for visibility.
+// Returns redis.Nil if the queue is empty. Payloads that aren't a JSON job
+// with an ID are moved to the DLQ on the way (see Worker.deadLetterMalformed).
+func (q *Queue) Dequeue(ctx context.Context, visibility time.Duration) (Delivery, error) {
+	receipt, err := newReceipt()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	// Pop + record as in-flight in one atomic step, so a crash can't lose the job.
+	// Redis doesn't roll back a script's writes when it errors, so the decode is
+	// guarded with pcall: a bad payload must not fail the script after the pop.
+	script := `
+		while true do
+			local data = redis.call("rpop", KEYS[1])
+			if not data then
+				return false
+			end
+			local ok, job = pcall(cjson.decode, data)
+			if ok and type(job) == "table" and type(job["id"]) == "string" and job["id"] ~= "" then
+				redis.call("zadd", KEYS[2], ARGV[1], job["id"])
+				redis.call("hset", KEYS[3], job["id"], data)
+				redis.call("hset", KEYS[5], job["id"], ARGV[3])
+				return data
+			end
+			redis.call("lpush", KEYS[4], cjson.encode({
+				type = "malformed",
+				payload = data,
+				failure_reason = "malformed job: not JSON with an id",
+				failed_at = ARGV[2],
+			}))
+		end
+	`
+	now := time.Now()
+	deadline := now.Add(visibility).UnixMilli()
+	data, err := q.client.Eval(ctx, script,
+		[]string{q.readyKey(), q.inflightKey(), q.inflightDataKey(), q.dlqKey(), q.inflightReceiptsKey()},
+		deadline, now.Format(time.RFC3339Nano), receipt).Text()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return Delivery{}, err
+	}
+	q.setStatus(ctx, job.ID, StatusProcessing, nil)
+	return Delivery{Job: job, Receipt: receipt}, nil
+}
+
+// Ack marks a delivered job as done. Returns false if the delivery is no
+// longer current (e.g. its visibility timeout expired and the job was
+// redelivered, possibly to another consumer).
+func (q *Queue) Ack(ctx context.Context, d Delivery) (bool, error) {
+	script := `
+		if redis.call("hget", KEYS[3], ARGV[1]) ~= ARGV[2] then
+			return 0
+		end
+		redis.call("zrem", KEYS[1], ARGV[1])
+		redis.call("hdel", KEYS[2], ARGV[1])
+		redis.call("hdel", KEYS[3], ARGV[1])
+		return 1
+	`
+	acked, err := q.client.Eval(ctx, script,
+		[]string{q.inflightKey(), q.inflightDataKey(), q.inflightReceiptsKey()}, d.ID, d.Receipt).Bool()
+	if err != nil || !acked {
+		return false, err
+	}
+	return true, q.setStatus(ctx, d.ID, StatusDone, nil)
+}
+
+// Nack gives a delivered job back so it is redelivered immediately.
+// Returns false if the delivery is no longer current.
+func (q *Queue) Nack(ctx context.Context, d Delivery) (bool, error) {
+	script := `
+		if redis.call("hget", KEYS[3], ARGV[1]) ~= ARGV[2] then
+			return 0
+		end
+		local data = redis.call("hget", KEYS[2], ARGV[1])
+		redis.call("zrem", KEYS[1], ARGV[1])
+		redis.call("hdel", KEYS[2], ARGV[1])
+		redis.call("hdel", KEYS[3], ARGV[1])
+		if data then
+			redis.call("rpush", KEYS[4], data)
+		end
+		return 1
+	`
+	nacked, err := q.client.Eval(ctx, script,
+		[]string{q.inflightKey(), q.inflightDataKey(), q.inflightReceiptsKey(), q.readyKey()},
+		d.ID, d.Receipt).Bool()
+	if err != nil || !nacked {
+		return false, err
+	}
+	return true, q.setStatus(ctx, d.ID, StatusQueued, nil)
+}
+
+// RunReaper requeues in-flight jobs whose visibility deadline has passed
+// without an Ack or Nack, checking every interval until ctx is cancelled
+func (q *Queue) RunReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := q.reapExpired(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Reaper error: %v", err)
+			}
+		}
+	}
+}
+
+// reapExpired moves every expired in-flight job back to the tail of the
+// queue (so it is redelivered next) and returns how many were moved
+func (q *Queue) reapExpired(ctx context.Context) (int64, error) {
+	script := `
+		local expired = redis.call("zrangebyscore", KEYS[1], "-inf", ARGV[1])
+		for _, id in ipairs(expired) do
+			local data = redis.call("hget", KEYS[2], id)
+			if data then
+				redis.call("rpush", KEYS[3], data)
+			end
+			redis.call("zrem", KEYS[1], id)
+			redis.call("hdel", KEYS[2], id)
+			redis.call("hdel", KEYS[4], id)
+		end
+		return #expired
+	`
+	return q.client.Eval(ctx, script,
+		[]string{q.inflightKey(), q.inflightDataKey(), q.readyKey(), q.inflightReceiptsKey()},
+		time.Now().UnixMilli()).Int64()
+}
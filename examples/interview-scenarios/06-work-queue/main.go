@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -12,14 +12,6 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// Job represents a unit of work
-type Job struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	Payload   string    `json:"payload"`
-	CreatedAt time.Time `json:"created_at"`
-}
-
 func main() {
 	fmt.Println("⚙️  Redis Work Queue Demo")
 	fmt.Println("=======================")
@@ -28,39 +20,151 @@ func main() {
 	client := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 
 	// Clear previous queue
-	queueKey := "jobs:queue"
-	client.Del(ctx, queueKey)
+	queue := NewQueue(client, "jobs")
+	queue.Clear(ctx)
+
+	// Recover jobs that were mid-processing when a previous run crashed
+	recovered, err := queue.RecoverProcessing(ctx)
+	if err != nil {
+		log.Printf("Recovery error: %v", err)
+	} else if recovered > 0 {
+		fmt.Printf("♻️  Recovered %d unfinished jobs from a previous run\n", recovered)
+	}
 
 	var wg sync.WaitGroup
 
-	// Start Consumers (Workers)
-	numConsumers := 3
-	for i := 1; i <= numConsumers; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			runConsumer(ctx, client, id, queueKey)
-		}(i)
+	retryPolicy := RetryPolicy{MaxAttempts: 3, BaseBackoff: 500 * time.Millisecond}
+
+	// Schedule a job for later ("send this email in 2 seconds")
+	reminder := Job{ID: "job-reminder", Type: "email", Payload: "Reminder", CreatedAt: time.Now()}
+	if err := queue.EnqueueAt(ctx, reminder, time.Now().Add(2*time.Second)); err != nil {
+		log.Printf("Schedule error: %v", err)
+	} else {
+		fmt.Printf("⏰ Scheduled Job %s for 2s from now\n", reminder.ID)
 	}
 
+	// Start Consumers (Workers)
+	worker := NewWorker(queue, processJob, 3, retryPolicy)
+	worker.Start(ctx)
+
 	// Start Producer
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		runProducer(ctx, client, queueKey)
+		runProducer(ctx, queue)
 	}()
 
 	wg.Wait()
+
+	if m, err := worker.Metrics(ctx); err == nil {
+		fmt.Printf("📊 Depth: %d | Delayed: %d | Processing: %d | DLQ: %d | Processed: %d (%.1f/s) | Failed: %d\n",
+			m.Depth, m.Delayed, m.Processing, m.DLQ, m.Processed, m.Rate, m.Failed)
+	}
+
+	// Let the consumers drain the queue (including delayed retries), then stop them
+	waitForDrain(ctx, queue, 30*time.Second)
+	if !worker.Shutdown(5 * time.Second) {
+		fmt.Println("⚠️  Forced shutdown - unfinished jobs stay in processing lists for recovery")
+	}
+
+	// Check the final status of a couple of jobs
+	for _, jobID := range []string{"job-1", reminder.ID} {
+		if status, err := queue.GetStatus(ctx, jobID); err == nil {
+			fmt.Printf("📋 %s: %s\n", jobID, status)
+			if queued, ok := status.StateAt[StatusQueued]; ok {
+				fmt.Printf("   queued → last update: %v\n", status.UpdatedAt.Sub(queued))
+			}
+		}
+	}
+
+	// Show what ended up in the dead-letter queue
+	deadJobs, err := queue.InspectDLQ(ctx)
+	if err != nil {
+		log.Printf("DLQ error: %v", err)
+		return
+	}
+	fmt.Printf("\n🪦 Dead-letter queue: %d jobs\n", len(deadJobs))
+	for _, job := range deadJobs {
+		fmt.Printf("   %s (%s) after %d attempts: %s\n", job.ID, job.Type, job.Attempts, job.FailureReason)
+	}
+
+	// Priority queue: higher priority first, FIFO within the same priority
+	fmt.Println("\n🥇 Priority Queue")
+	for i, priority := range []int{1, 5, 1, 10, 5} {
+		job := Job{ID: fmt.Sprintf("prio-%d", i+1), Type: "report_gen", Priority: priority, CreatedAt: time.Now()}
+		queue.EnqueuePriority(ctx, job)
+		time.Sleep(time.Millisecond) // Distinct timestamps for the FIFO tiebreaker
+	}
+	for {
+		job, err := queue.DequeuePriority(ctx, time.Second)
+		if err != nil {
+			break
+		}
+		fmt.Printf("   Dequeued %s (priority %d)\n", job.ID, job.Priority)
+	}
+
+	// ACK/NACK with a visibility timeout (SQS-style)
+	fmt.Println("\n👻 Visibility Timeout")
+	go queue.RunReaper(ctx, 100*time.Millisecond)
+	queue.Enqueue(ctx, Job{ID: "job-sqs", Type: "email", CreatedAt: time.Now()})
+
+	first, err := queue.Dequeue(ctx, 500*time.Millisecond)
+	if err != nil {
+		log.Printf("Dequeue error: %v", err)
+		return
+	}
+	fmt.Printf("   Took %s and stalled without ACK...\n", first.ID)
+
+	time.Sleep(time.Second)
+	if second, err := queue.Dequeue(ctx, 500*time.Millisecond); err == nil {
+		fmt.Printf("   Redelivered %s after the visibility timeout\n", second.ID)
+		late, _ := queue.Ack(ctx, first)
+		fmt.Printf("   The stalled consumer's late ACK accepted: %v (stale receipt)\n", late)
+		acked, _ := queue.Ack(ctx, second)
+		fmt.Printf("   ACKed %s with the current receipt: %v\n", second.ID, acked)
+	}
+
+	// A worker in another process crashes mid-job while ours is busy
+	fmt.Println("\n💥 Crash Recovery")
+	slowJob := func(ctx context.Context, job Job) error {
+		time.Sleep(1500 * time.Millisecond)
+		return nil
+	}
+	survivor := NewWorker(queue, slowJob, 1, retryPolicy)
+	survivor.Start(ctx)
+	queue.Enqueue(ctx, Job{ID: "job-live", Type: "report_gen", CreatedAt: time.Now()})
+	time.Sleep(300 * time.Millisecond) // survivor is now processing job-live
+
+	// What BRPOPLPUSH left behind on a host that died: a job in its
+	// processing list and no heartbeat key
+	queue.Enqueue(ctx, Job{ID: "job-orphan", Type: "email", CreatedAt: time.Now()})
+	client.LMove(ctx, queue.readyKey(), queue.processingKey("dead-host-42-beef", 1), "RIGHT", "LEFT")
+	fmt.Println("   dead-host-42-beef crashed holding job-orphan")
+
+	recovered, err = queue.RecoverProcessing(ctx)
+	if err != nil {
+		log.Printf("Recovery error: %v", err)
+	}
+	fmt.Printf("   RecoverProcessing requeued %d job (job-live stays with the live worker)\n", recovered)
+
+	waitForDrain(ctx, queue, 10*time.Second)
+	survivor.Shutdown(5 * time.Second)
+	for _, jobID := range []string{"job-live", "job-orphan"} {
+		if status, err := queue.GetStatus(ctx, jobID); err == nil {
+			fmt.Printf("📋 %s: %s\n", jobID, status)
+		}
+	}
 }
 
-func runProducer(ctx context.Context, client *redis.Client, queueKey string) {
+func runProducer(ctx context.Context, queue *Queue) {
 	jobTypes := []string{"email", "image_process", "report_gen"}
 
 	for i := 1; i <= 10; i++ {
@@ -71,11 +175,7 @@ func runProducer(ctx context.Context, client *redis.Client, queueKey string) {
 			CreatedAt: time.Now(),
 		}
 
-		data, _ := json.Marshal(job)
-
-		// LPUSH: Add to head of list
-		err := client.LPush(ctx, queueKey, data).Err()
-		if err != nil {
+		if err := queue.Enqueue(ctx, job); err != nil {
 			log.Printf("Producer error: %v", err)
 		} else {
 			fmt.Printf("📤 Produced Job %s (%s)\n", job.ID, job.Type)
@@ -84,38 +184,29 @@ func runProducer(ctx context.Context, client *redis.Client, queueKey string) {
 		time.Sleep(time.Duration(rand.Intn(500)+200) * time.Millisecond)
 	}
 
-	// Signal end by sending "poison pills" (optional, but good for graceful shutdown)
-	// Here we just let consumers timeout after a while
 	fmt.Println("✅ Producer finished sending 10 jobs")
 }
 
-func runConsumer(ctx context.Context, client *redis.Client, id int, queueKey string) {
-	fmt.Printf("👷 Consumer %d started\n", id)
+// processJob is the demo HandlerFunc: it simulates doing the work,
+// and some jobs fail randomly
+func processJob(ctx context.Context, job Job) error {
+	// Simulate processing time
+	processTime := time.Duration(rand.Intn(1000)+500) * time.Millisecond
+	time.Sleep(processTime)
 
-	for {
-		// BRPOP: Blocking pop from tail of list (timeout 5 seconds)
-		// Returns [key, value]
-		result, err := client.BRPop(ctx, 5*time.Second, queueKey).Result()
+	if rand.Intn(4) == 0 {
+		return errors.New("simulated failure")
+	}
+	return nil
+}
 
-		if err == redis.Nil {
-			fmt.Printf("💤 Consumer %d timed out (no jobs)\n", id)
-			break
-		} else if err != nil {
-			log.Printf("Consumer %d error: %v", id, err)
-			break
+// waitForDrain polls until no jobs are pending (or timeout passes)
+func waitForDrain(ctx context.Context, queue *Queue, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if pending, err := queue.Pending(ctx); err == nil && pending == 0 {
+			return
 		}
-
-		// result[0] is key, result[1] is value
-		jobData := result[1]
-		var job Job
-		json.Unmarshal([]byte(jobData), &job)
-
-		fmt.Printf("   ⚙️  Consumer %d processing %s (%s)...\n", id, job.ID, job.Type)
-
-		// Simulate processing time
-		processTime := time.Duration(rand.Intn(1000)+500) * time.Millisecond
-		time.Sleep(processTime)
-
-		fmt.Printf("   ✅ Consumer %d finished %s\n", id, job.ID)
+		time.Sleep(200 * time.Millisecond)
 	}
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateWindow is how many seconds the processed-per-second rate averages over
+const rateWindow = 10
+
+// QueueMetrics is a snapshot of queue health for operators
+type QueueMetrics struct {
+	Depth      int64   // Jobs waiting in the ready list (LLEN)
+	Delayed    int64   // Jobs scheduled for later
+	Processing int64   // Jobs currently being worked on
+	DLQ        int64   // Dead-lettered jobs
+	Processed  int64   // Jobs completed by this worker since start
+	Failed     int64   // Failed attempts seen by this worker since start
+	Rate       float64 // Jobs completed per second, averaged over rateWindow
+}
+
+// rateCounter counts events in per-second buckets. Each bucket remembers
+// which second it belongs to and is reset when reused. The reset and the
+// increments share one mutex: with atomics alone, a goroutine resetting a
+// stale bucket could wipe increments another goroutine had just made.
+type rateCounter struct {
+	mu      sync.Mutex
+	buckets [rateWindow]struct {
+		second int64
+		count  int64
+	}
+}
+
+func (c *rateCounter) add() {
+	now := time.Now().Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := &c.buckets[now%rateWindow]
+	if b.second != now {
+		b.second, b.count = now, 0
+	}
+	b.count++
+}
+
+// perSecond averages the buckets that fall inside the window
+func (c *rateCounter) perSecond() float64 {
+	now := time.Now().Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total int64
+	for _, b := range c.buckets {
+		if now-b.second < rateWindow {
+			total += b.count
+		}
+	}
+	return float64(total) / rateWindow
+}
+
+// Metrics reports backlog from Redis plus this worker's throughput counters.
+// A growing Depth with a flat Rate means consumers can't keep up.
+func (w *Worker) Metrics(ctx context.Context) (QueueMetrics, error) {
+	q := w.queue
+	m := QueueMetrics{
+		Processed: w.processed.Load(),
+		Failed:    w.failed.Load(),
+		Rate:      w.rate.perSecond(),
+	}
+
+	var err error
+	if m.Depth, err = q.client.LLen(ctx, q.readyKey()).Result(); err != nil {
+		return m, err
+	}
+	if m.Delayed, err = q.client.ZCard(ctx, q.delayedKey()).Result(); err != nil {
+		return m, err
+	}
+	if m.DLQ, err = q.client.LLen(ctx, q.dlqKey()).Result(); err != nil {
+		return m, err
+	}
+
+	// In progress = per-consumer processing lists + visibility-timeout in-flight set
+	iter := q.client.Scan(ctx, 0, q.prefix+":processing:*", 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := q.client.LLen(ctx, iter.Val()).Result()
+		if err != nil {
+			return m, err
+		}
+		m.Processing += n
+	}
+	if err := iter.Err(); err != nil {
+		return m, err
+	}
+	inflight, err := q.client.ZCard(ctx, q.inflightKey()).Result()
+	if err != nil {
+		return m, err
+	}
+	m.Processing += inflight
+
+	return m, nil
+}
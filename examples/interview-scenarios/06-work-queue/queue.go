@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Job represents a unit of work
+type Job struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	Attempts  int       `json:"attempts"` // Failed processing attempts so far
+	Priority  int       `json:"priority"` // Higher runs first (priority queue only, 0-MaxPriority)
+
+	// Set when the job is moved to the dead-letter queue
+	FailureReason string    `json:"failure_reason,omitempty"`
+	FailedAt      time.Time `json:"failed_at,omitempty"`
+}
+
+// Queue is a reliable Redis-backed job queue.
+// All keys share a prefix, e.g. with prefix "jobs":
+//
+//	jobs:queue               LIST   ready jobs (LPUSH in, BRPOPLPUSH out)
+//	jobs:processing:<w>:<n>  LIST   job consumer n of worker w is processing
+//	jobs:heartbeat:<w>       STRING set while worker w is alive (expires)
+//	jobs:delayed             ZSET   scheduled jobs scored by due time
+//	jobs:priority            ZSET   priority jobs (see priorityScore)
+//	jobs:dlq                 LIST   dead-lettered jobs
+//	jobs:status:<id>         HASH   job status
+type Queue struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewQueue(client *redis.Client, prefix string) *Queue {
+	return &Queue{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (q *Queue) readyKey() string    { return q.prefix + ":queue" }
+func (q *Queue) dlqKey() string      { return q.prefix + ":dlq" }
+func (q *Queue) delayedKey() string  { return q.prefix + ":delayed" }
+func (q *Queue) priorityKey() string { return q.prefix + ":priority" }
+
+// processingKey is the per-consumer list holding the job currently being processed.
+// It includes the worker ID so consumers in different processes never share a list.
+func (q *Queue) processingKey(workerID string, consumer int) string {
+	return fmt.Sprintf("%s:processing:%s:%d", q.prefix, workerID, consumer)
+}
+
+// heartbeatKey exists (with a short TTL) while the worker is alive
+func (q *Queue) heartbeatKey(workerID string) string {
+	return q.prefix + ":heartbeat:" + workerID
+}
+
+// processingOwner extracts the worker ID from a processing list key
+func (q *Queue) processingOwner(key string) string {
+	owner := strings.TrimPrefix(key, q.prefix+":processing:")
+	if i := strings.LastIndex(owner, ":"); i >= 0 {
+		owner = owner[:i]
+	}
+	return owner
+}
+
+// Clear deletes the queue's lists and sets (status entries expire on their own)
+func (q *Queue) Clear(ctx context.Context) error {
+	return q.client.Del(ctx, q.readyKey(), q.dlqKey(), q.delayedKey(), q.priorityKey(),
+		q.inflightKey(), q.inflightDataKey(), q.inflightReceiptsKey()).Err()
+}
+
+// Enqueue adds a job to the queue
+func (q *Queue) Enqueue(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	// LPUSH: Add to head of list. MULTI/EXEC so a worker can't pop the job
+	// before its "queued" status exists and have it overwritten afterwards.
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, q.readyKey(), data)
+		q.pipeStatus(ctx, pipe, job.ID, StatusQueued, nil)
+		return nil
+	})
+	return err
+}
+
+// EnqueueAt schedules a job to become available on the queue at time t.
+// The job sits in a sorted set scored by its due time until the mover
+// (RunDelayedMover) moves it onto the queue.
+func (q *Queue) EnqueueAt(ctx context.Context, job Job, t time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, q.delayedKey(), redis.Z{
+			Score:  float64(t.UnixMilli()),
+			Member: data,
+		})
+		q.pipeStatus(ctx, pipe, job.ID, StatusQueued, nil)
+		return nil
+	})
+	return err
+}
+
+// RunDelayedMover polls the delayed set every interval and moves due jobs
+// onto the queue until ctx is cancelled. Safe to run several movers at once.
+func (q *Queue) RunDelayedMover(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.moveDueJobs(ctx); err != nil && err != redis.TxFailedErr && ctx.Err() == nil {
+				log.Printf("Delayed mover error: %v", err)
+			}
+		}
+	}
+}
+
+// moveDueJobs moves every job whose due time has passed onto the queue.
+// WATCH + MULTI makes the ZREM/LPUSH pair exactly-once: if another mover
+// touches the delayed set first, our EXEC aborts (TxFailedErr) and we simply
+// try again on the next tick.
+func (q *Queue) moveDueJobs(ctx context.Context) error {
+	return q.client.Watch(ctx, func(tx *redis.Tx) error {
+		due, err := tx.ZRangeByScore(ctx, q.delayedKey(), &redis.ZRangeBy{
+			Min: "-inf",
+			Max: fmt.Sprint(time.Now().UnixMilli()),
+		}).Result()
+		if err != nil || len(due) == 0 {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, data := range due {
+				pipe.ZRem(ctx, q.delayedKey(), data)
+				pipe.LPush(ctx, q.readyKey(), data)
+			}
+			return nil
+		})
+		return err
+	}, q.delayedKey())
+}
+
+// InspectDLQ returns every job in the dead-letter queue, newest first
+func (q *Queue) InspectDLQ(ctx context.Context) ([]Job, error) {
+	entries, err := q.client.LRange(ctx, q.dlqKey(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0, len(entries))
+	for _, data := range entries {
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Requeue moves a job from the dead-letter queue back onto the queue with a
+// fresh attempt counter (e.g. after fixing the bug that made it fail)
+func (q *Queue) Requeue(ctx context.Context, jobID string) error {
+	// WATCH the DLQ so a concurrent Requeue of the same job can't duplicate it
+	return q.client.Watch(ctx, func(tx *redis.Tx) error {
+		entries, err := tx.LRange(ctx, q.dlqKey(), 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, data := range entries {
+			var job Job
+			if err := json.Unmarshal([]byte(data), &job); err != nil || job.ID != jobID {
+				continue
+			}
+
+			job.Attempts = 0
+			job.FailureReason = ""
+			job.FailedAt = time.Time{}
+			updated, err := json.Marshal(job)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.LRem(ctx, q.dlqKey(), 1, data)
+				pipe.LPush(ctx, q.readyKey(), updated)
+				return nil
+			})
+			return err
+		}
+
+		return fmt.Errorf("job %s not found in DLQ", jobID)
+	}, q.dlqKey())
+}
+
+// priorityScore ranks by priority first, then by age: the timestamp is
+// subtracted so that, within one priority, older jobs score higher and
+// ZPOPMAX hands them out first (FIFO).
+// Scores must stay below 2^53 (~9.007e15) to be exact in a float64, which
+// caps priority at MaxPriority: 900e13 minus a current timestamp fits, 901e13 doesn't.
+func priorityScore(priority int, enqueuedAt time.Time) float64 {
+	return float64(priority)*1e13 - float64(enqueuedAt.UnixMilli())
+}
+
+// MaxPriority is the highest priority EnqueuePriority accepts (see priorityScore)
+const MaxPriority = 900
+
+// EnqueuePriority adds a job to the priority queue
+func (q *Queue) EnqueuePriority(ctx context.Context, job Job) error {
+	if job.Priority < 0 || job.Priority > MaxPriority {
+		return fmt.Errorf("job %s: priority %d out of range 0-%d", job.ID, job.Priority, MaxPriority)
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return q.client.ZAdd(ctx, q.priorityKey(), redis.Z{
+		Score:  priorityScore(job.Priority, time.Now()),
+		Member: data,
+	}).Err()
+}
+
+// DequeuePriority blocks (up to timeout) for the highest-priority job.
+// Returns redis.Nil if nothing arrived in time.
+func (q *Queue) DequeuePriority(ctx context.Context, timeout time.Duration) (Job, error) {
+	// BZPOPMAX: blocking pop of the highest-scored member
+	result, err := q.client.BZPopMax(ctx, timeout, q.priorityKey()).Result()
+	if err != nil {
+		return Job{}, err
+	}
+
+	var job Job
+	err = json.Unmarshal([]byte(result.Member.(string)), &job)
+	return job, err
+}
+
+// RecoverProcessing moves jobs left in the processing lists of dead workers
+// (whose heartbeat has expired) back onto the queue. Lists owned by live
+// workers, in this process or any other, are left alone, so it is safe to
+// call at any time. Returns the number of jobs recovered.
+func (q *Queue) RecoverProcessing(ctx context.Context) (int, error) {
+	recovered := 0
+
+	iter := q.client.Scan(ctx, 0, q.prefix+":processing:*", 100).Iterator()
+	for iter.Next(ctx) {
+		alive, err := q.client.Exists(ctx, q.heartbeatKey(q.processingOwner(iter.Val()))).Result()
+		if err != nil {
+			return recovered, err
+		}
+		if alive > 0 {
+			continue
+		}
+
+		for {
+			// Oldest job sits at the tail; put it on the queue tail so it's picked up next
+			err := q.client.LMove(ctx, iter.Val(), q.readyKey(), "RIGHT", "RIGHT").Err()
+			if err == redis.Nil {
+				break
+			} else if err != nil {
+				return recovered, err
+			}
+			recovered++
+		}
+	}
+
+	return recovered, iter.Err()
+}
+
+// Pending counts jobs not yet finished: ready, delayed, and in processing
+func (q *Queue) Pending(ctx context.Context) (int64, error) {
+	ready, err := q.client.LLen(ctx, q.readyKey()).Result()
+	if err != nil {
+		return 0, err
+	}
+	delayed, err := q.client.ZCard(ctx, q.delayedKey()).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := ready + delayed
+	iter := q.client.Scan(ctx, 0, q.prefix+":processing:*", 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := q.client.LLen(ctx, iter.Val()).Result()
+		if err != nil {
+			return 0, err
+		}
+		pending += n
+	}
+	return pending, iter.Err()
+}
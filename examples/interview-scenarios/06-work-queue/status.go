@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Job states as seen by GetStatus
+const (
+	StatusQueued     = "queued"
+	StatusProcessing = "processing"
+	StatusDone       = "done"
+	StatusFailed     = "failed"
+)
+
+// statusTTL is how long a status entry lives after its last update,
+// so finished jobs clean themselves up
+const statusTTL = 24 * time.Hour
+
+// JobStatus is the progress of one job, e.g. for a web UI to poll
+type JobStatus struct {
+	State     string
+	Worker    string // Consumer currently/last processing the job
+	Attempts  int
+	Result    string
+	Error     string
+	UpdatedAt time.Time
+	StateAt   map[string]time.Time // When the job last entered each state it has passed through
+}
+
+// statusKey is the hash holding a job's status: <prefix>:status:<id>
+func (q *Queue) statusKey(jobID string) string {
+	return q.prefix + ":status:" + jobID
+}
+
+// setStatus records a state transition plus any extra fields, and refreshes the TTL.
+// A "<state>_at" timestamp is kept for every state the job has passed through.
+func (q *Queue) setStatus(ctx context.Context, jobID, state string, fields map[string]interface{}) error {
+	_, err := q.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		q.pipeStatus(ctx, pipe, jobID, state, fields)
+		return nil
+	})
+	return err
+}
+
+// pipeStatus queues a status update on pipe, e.g. inside a MULTI/EXEC
+func (q *Queue) pipeStatus(ctx context.Context, pipe redis.Pipeliner, jobID, state string, fields map[string]interface{}) {
+	now := time.Now().UnixMilli()
+
+	values := map[string]interface{}{
+		"state":       state,
+		"updated_at":  now,
+		state + "_at": now,
+	}
+	for field, value := range fields {
+		values[field] = value
+	}
+
+	key := q.statusKey(jobID)
+	pipe.HSet(ctx, key, values)
+	pipe.Expire(ctx, key, statusTTL)
+}
+
+// GetStatus returns the current status of a job.
+// Returns redis.Nil if the job is unknown (or its status has expired).
+func (q *Queue) GetStatus(ctx context.Context, jobID string) (JobStatus, error) {
+	fields, err := q.client.HGetAll(ctx, q.statusKey(jobID)).Result()
+	if err != nil {
+		return JobStatus{}, err
+	}
+	if len(fields) == 0 {
+		return JobStatus{}, redis.Nil
+	}
+
+	status := JobStatus{
+		State:   fields["state"],
+		Worker:  fields["worker"],
+		Result:  fields["result"],
+		Error:   fields["error"],
+		StateAt: make(map[string]time.Time),
+	}
+	if attempts, err := strconv.Atoi(fields["attempts"]); err == nil {
+		status.Attempts = attempts
+	}
+	if ms, err := strconv.ParseInt(fields["updated_at"], 10, 64); err == nil {
+		status.UpdatedAt = time.UnixMilli(ms)
+	}
+	for field, value := range fields {
+		state, ok := strings.CutSuffix(field, "_at")
+		if !ok || state == "updated" {
+			continue
+		}
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			status.StateAt[state] = time.UnixMilli(ms)
+		}
+	}
+	return status, nil
+}
+
+// String formats a status for logging
+func (s JobStatus) String() string {
+	out := fmt.Sprintf("%s (attempts: %d", s.State, s.Attempts)
+	if s.Worker != "" {
+		out += ", worker: " + s.Worker
+	}
+	if s.Error != "" {
+		out += ", error: " + s.Error
+	}
+	return out + ")"
+}
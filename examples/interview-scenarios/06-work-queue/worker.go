@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HandlerFunc processes one job. Returning an error triggers the retry
+// policy (and eventually the dead-letter queue).
+type HandlerFunc func(ctx context.Context, job Job) error
+
+// RetryPolicy controls what happens when processing a job fails
+type RetryPolicy struct {
+	MaxAttempts int           // Give up after this many failed attempts
+	BaseBackoff time.Duration // Delay before the first retry (doubles each time, 0 = retry immediately)
+}
+
+// backoff returns how long to wait before retrying after the given attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseBackoff <= 0 || attempt < 1 {
+		return 0
+	}
+	return p.BaseBackoff << (attempt - 1)
+}
+
+// heartbeatTTL is how long a worker counts as alive after its last heartbeat.
+// RecoverProcessing only touches processing lists of workers past this.
+const heartbeatTTL = 5 * time.Second
+
+// delayedMoverInterval is how often a worker's mover checks for due delayed
+// jobs, which includes its own retries with backoff
+const delayedMoverInterval = 100 * time.Millisecond
+
+// Worker runs a pool of reliable consumers that pass each job to a handler
+type Worker struct {
+	id          string // Unique per process: hostname-pid-random
+	queue       *Queue
+	handler     HandlerFunc
+	concurrency int
+	policy      RetryPolicy
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Throughput counters (see Metrics)
+	processed atomic.Int64
+	failed    atomic.Int64
+	rate      rateCounter
+}
+
+func NewWorker(queue *Queue, handler HandlerFunc, concurrency int, policy RetryPolicy) *Worker {
+	return &Worker{
+		id:          newWorkerID(),
+		queue:       queue,
+		handler:     handler,
+		concurrency: concurrency,
+		policy:      policy,
+	}
+}
+
+// newWorkerID identifies a worker across processes. The random suffix keeps
+// a restarted process that reuses a PID (e.g. PID 1 in a container) from
+// claiming the crashed process's lists as its own.
+func newWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%04x", host, os.Getpid(), rand.Intn(1<<16))
+}
+
+// Start launches the consumers and a delayed-job mover, so retries with
+// backoff come back on their own; they run until ctx is cancelled or Shutdown
+func (w *Worker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.queue.RunDelayedMover(ctx, delayedMoverInterval)
+
+	// First heartbeat before any consumer takes a job, so no recoverer
+	// ever sees our processing lists without a live heartbeat
+	w.beat(context.WithoutCancel(ctx))
+
+	for i := 1; i <= w.concurrency; i++ {
+		w.wg.Add(1)
+		go func(id int) {
+			defer w.wg.Done()
+			w.consume(ctx, id)
+		}(i)
+	}
+
+	consumersDone := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(consumersDone)
+	}()
+	go w.heartbeat(context.WithoutCancel(ctx), consumersDone)
+}
+
+// heartbeat refreshes the worker's heartbeat key every heartbeatTTL/3.
+// It keeps going after ctx is cancelled until the last consumer returns,
+// since in-flight jobs are still being finished. If the process dies, the
+// key expires and RecoverProcessing takes over its jobs.
+func (w *Worker) heartbeat(ctx context.Context, consumersDone <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-consumersDone:
+			// Every job finished and its processing list is empty
+			w.queue.client.Del(ctx, w.queue.heartbeatKey(w.id))
+			return
+		case <-ticker.C:
+			w.beat(ctx)
+		}
+	}
+}
+
+// beat marks the worker alive for another heartbeatTTL
+func (w *Worker) beat(ctx context.Context) {
+	if err := w.queue.client.Set(ctx, w.queue.heartbeatKey(w.id), time.Now().UnixMilli(), heartbeatTTL).Err(); err != nil {
+		log.Printf("Worker %s heartbeat error: %v", w.id, err)
+	}
+}
+
+// Shutdown stops the consumers from taking new jobs and waits up to timeout
+// for in-flight jobs to finish. Returns false if the timeout hit first; those
+// jobs are still in their processing lists, and once this process exits and
+// its heartbeat expires, RecoverProcessing requeues them.
+func (w *Worker) Shutdown(timeout time.Duration) bool {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// consume is one reliable consumer loop. It never loses a job on crash:
+// BRPOPLPUSH atomically moves the job from the queue into this consumer's
+// processing list; it is only removed (LREM) once processing succeeds.
+// If the process dies in between, the job is still in the processing list
+// and RecoverProcessing puts it back on the queue once the heartbeat lapses.
+//
+// It runs until ctx is cancelled: the job in progress is finished, then the
+// consumer stops pulling new ones and returns.
+// Redis errors are logged and retried with backoff (100ms doubling to 5s);
+// payloads that aren't valid JSON go straight to the dead-letter queue.
+func (w *Worker) consume(ctx context.Context, id int) {
+	fmt.Printf("👷 Consumer %d started (reliable)\n", id)
+	client := w.queue.client
+	inFlight := w.queue.processingKey(w.id, id)
+
+	// Redis calls for a job we've already taken must not be cut off by
+	// shutdown, or the job would be left half-acknowledged
+	workCtx := context.WithoutCancel(ctx)
+
+	var errBackoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("🛑 Consumer %d stopped\n", id)
+			return
+		default:
+		}
+
+		// BRPOPLPUSH: pop from queue tail, push to our processing list, atomically
+		// Short timeout so we notice shutdown quickly when idle
+		jobData, err := client.BRPopLPush(workCtx, w.queue.readyKey(), inFlight, time.Second).Result()
+
+		if err == redis.Nil {
+			errBackoff = 0
+			continue
+		} else if err != nil {
+			// Redis hiccup (failover, network blip): wait and try again
+			errBackoff = min(max(2*errBackoff, 100*time.Millisecond), 5*time.Second)
+			log.Printf("Consumer %d error: %v (retrying in %v)", id, err, errBackoff)
+			select {
+			case <-ctx.Done():
+			case <-time.After(errBackoff):
+			}
+			continue
+		}
+		errBackoff = 0
+
+		var job Job
+		if err := json.Unmarshal([]byte(jobData), &job); err != nil {
+			// Retrying can't fix a malformed payload - park it in the DLQ as-is
+			log.Printf("Consumer %d got malformed job: %v", id, err)
+			if err := w.deadLetterMalformed(workCtx, inFlight, jobData, err); err != nil {
+				log.Printf("Consumer %d failed to dead-letter malformed job: %v", id, err)
+			}
+			w.failed.Add(1)
+			continue
+		}
+
+		fmt.Printf("   ⚙️  Consumer %d processing %s (%s, attempt %d)...\n", id, job.ID, job.Type, job.Attempts+1)
+		w.queue.setStatus(workCtx, job.ID, StatusProcessing, map[string]interface{}{
+			"worker": fmt.Sprintf("%s/consumer-%d", w.id, id),
+		})
+
+		if err := w.handler(workCtx, job); err != nil {
+			w.failed.Add(1)
+			// Failed - retry or give up (a crash before this completes keeps the job safe)
+			if err := w.retryOrFail(workCtx, inFlight, jobData, job, err); err != nil {
+				log.Printf("Consumer %d failed to retry %s: %v", id, job.ID, err)
+			}
+			continue
+		}
+
+		// Done - remove from processing list (a crash before this line keeps the job safe)
+		if err := client.LRem(workCtx, inFlight, 1, jobData).Err(); err != nil {
+			log.Printf("Consumer %d failed to clear %s: %v", id, job.ID, err)
+		}
+		w.queue.setStatus(workCtx, job.ID, StatusDone, map[string]interface{}{"result": "ok"})
+		w.processed.Add(1)
+		w.rate.add()
+
+		fmt.Printf("   ✅ Consumer %d finished %s\n", id, job.ID)
+	}
+}
+
+// deadLetterMalformed moves a payload that isn't a valid Job from the
+// processing list to the DLQ, wrapped in a Job so InspectDLQ can still read it
+func (w *Worker) deadLetterMalformed(ctx context.Context, inFlight, jobData string, cause error) error {
+	q := w.queue
+	wrapped, err := json.Marshal(Job{
+		Type:          "malformed",
+		Payload:       jobData,
+		FailureReason: cause.Error(),
+		FailedAt:      time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, inFlight, 1, jobData)
+		pipe.LPush(ctx, q.dlqKey(), wrapped)
+		return nil
+	})
+	return err
+}
+
+// retryOrFail records a failed attempt. Below policy.MaxAttempts the job is
+// pushed back on the queue with Attempts incremented; after that it is moved
+// to the dead-letter queue with the failure reason. Either way it leaves the
+// processing list in the same MULTI/EXEC, so the job is never lost or duplicated.
+func (w *Worker) retryOrFail(ctx context.Context, inFlight, jobData string, job Job, cause error) error {
+	q := w.queue
+	job.Attempts++
+
+	target := q.readyKey()
+	state := StatusQueued
+	var delay time.Duration
+	if job.Attempts >= w.policy.MaxAttempts {
+		target = q.dlqKey()
+		state = StatusFailed
+		job.FailureReason = cause.Error()
+		job.FailedAt = time.Now()
+		fmt.Printf("   💀 %s failed %d times, moved to DLQ: %v\n", job.ID, job.Attempts, cause)
+	} else {
+		delay = w.policy.backoff(job.Attempts)
+		fmt.Printf("   🔁 %s failed (attempt %d/%d), retrying in %v\n", job.ID, job.Attempts, w.policy.MaxAttempts, delay)
+	}
+
+	updated, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, inFlight, 1, jobData)
+
+		q.pipeStatus(ctx, pipe, job.ID, state, map[string]interface{}{
+			"attempts": job.Attempts,
+			"error":    cause.Error(),
+		})
+
+		if delay > 0 {
+			// Backoff: park it in the delayed set; the mover requeues it when due
+			pipe.ZAdd(ctx, q.delayedKey(), redis.Z{
+				Score:  float64(time.Now().Add(delay).UnixMilli()),
+				Member: updated,
+			})
+		} else {
+			pipe.LPush(ctx, target, updated)
+		}
+		return nil
+	})
+	return err
+}
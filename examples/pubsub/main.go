@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
@@ -83,6 +84,18 @@ func main() {
 	// Demo 5: Cache Invalidation Pattern
 	demo5CacheInvalidation(client)
 
+	// Demo 6: Typed Topics
+	demo6TypedTopic(client)
+
+	// Demo 7: Surviving Connection Drops
+	demo7ResilientSubscriber(client)
+
+	// Demo 8: Message Router
+	demo8Router(client)
+
+	// Demo 9: Request/Reply
+	demo9RequestReply(client)
+
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║          Pub/Sub is great for real-time broadcasts! 🎉       ║")
@@ -383,8 +396,162 @@ func demo5CacheInvalidation(client *redis.Client) {
 	fmt.Println()
 }
 
+// PriceUpdate is an example typed pub/sub message
+type PriceUpdate struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+// Demo 6: Typed Topics (JSON payloads)
+func demo6TypedTopic(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 6: Typed Topics (JSON payloads)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prices := NewTopic[PriceUpdate](client, "prices")
+	updates, err := prices.Subscribe(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("✓ Subscribed to typed topic: prices")
+
+	prices.Publish(ctx, PriceUpdate{Symbol: "REDIS", Price: 42.5})
+	client.Publish(ctx, "prices", "not json") // Skipped and logged by the subscriber
+	prices.Publish(ctx, PriceUpdate{Symbol: "GO", Price: 99.9})
+
+	for i := 0; i < 2; i++ {
+		update := <-updates
+		fmt.Printf("  [Subscriber] %s → $%.2f\n", update.Symbol, update.Price)
+	}
+	fmt.Println()
+}
+
+// Demo 7: Surviving Connection Drops
+func demo7ResilientSubscriber(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 7: Surviving Connection Drops")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := NewResilientSubscriber(client, []string{"alerts"}, []string{"alerts:*"})
+	go sub.Run(ctx)
+
+	waitConnected := func() {
+		for event := range sub.Events() {
+			if event.Connected {
+				fmt.Printf("  🔌 Subscribed (after %d reconnect attempts)\n", event.Attempt)
+				return
+			}
+			fmt.Printf("  ⚠️  Connection lost (attempt %d): %v\n", event.Attempt, event.Err)
+		}
+	}
+	waitConnected()
+
+	client.Publish(ctx, "alerts", "disk 80% full")
+	fmt.Printf("  [Subscriber] %s\n", (<-sub.Messages()).Payload)
+
+	// Simulate a network blip: kill every pub/sub connection on the server
+	fmt.Println("  💥 Killing pub/sub connections (CLIENT KILL TYPE pubsub)")
+	client.ClientKillByFilter(ctx, "TYPE", "pubsub")
+	waitConnected()
+
+	client.Publish(ctx, "alerts:disk", "disk 95% full")
+	fmt.Printf("  [Subscriber] %s (still delivering)\n", (<-sub.Messages()).Payload)
+	fmt.Println()
+}
+
+// Demo 8: Message Router (handlers per channel / pattern)
+func demo8Router(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 8: Message Router")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	router := NewRouter(client)
+	router.Handle("user:*", func(channel, payload string) {
+		defer wg.Done()
+		fmt.Printf("  [user:* handler] %s: %s\n", channel, payload)
+	})
+	router.Handle("user:123:login", func(channel, payload string) {
+		defer wg.Done()
+		fmt.Printf("  [user:123:login handler] %s: %s\n", channel, payload)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := router.Run(ctx); err != nil {
+			log.Printf("Router error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond) // Let the subscriptions land
+
+	// Matches both registrations → both handlers fire
+	wg.Add(2)
+	client.Publish(ctx, "user:123:login", "User 123 logged in")
+	// Matches only the pattern
+	wg.Add(1)
+	client.Publish(ctx, "user:456:logout", "User 456 logged out")
+
+	wg.Wait()
+	cancel()
+	<-done
+	fmt.Println()
+}
+
+// Demo 9: Request/Reply (RPC over pub/sub)
+func demo9RequestReply(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 9: Request/Reply (RPC over Pub/Sub)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Server: uppercases whatever it gets
+	go Serve(ctx, client, "rpc:upper", func(payload []byte) ([]byte, error) {
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("empty payload")
+		}
+		return bytes.ToUpper(payload), nil
+	})
+	time.Sleep(100 * time.Millisecond) // Let the server subscribe
+
+	rpc, err := NewRPCClient(ctx, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rpc.Close()
+
+	for _, payload := range []string{"hello redis", ""} {
+		reqCtx, reqCancel := context.WithTimeout(ctx, time.Second)
+		reply, err := rpc.Request(reqCtx, "rpc:upper", []byte(payload))
+		reqCancel()
+		if err != nil {
+			fmt.Printf("  → Request %q failed: %v\n", payload, err)
+			continue
+		}
+		fmt.Printf("  → Request %q, reply %q\n", payload, reply)
+	}
+
+	_, err = rpc.Request(ctx, "rpc:nobody-home", []byte("ping"))
+	fmt.Printf("  → Request to unserved channel: %v\n", err)
+	fmt.Println()
+}
+
 // InteractiveMode allows running pub/sub interactively
-// Run with: go run main.go interactive
+// Run with: go run . interactive
 func InteractiveMode(client *redis.Client) {
 	fmt.Println("═══════════════════════════════════════════════════════════════")
 	fmt.Println(" Interactive Mode")
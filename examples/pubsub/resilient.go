@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 5 * time.Second
+)
+
+// ConnectionEvent reports a subscriber losing or regaining its connection
+type ConnectionEvent struct {
+	Connected bool  // true = (re)subscribed, false = connection lost
+	Attempt   int   // Reconnect attempts since the connection was lost
+	Err       error // Why the connection was lost (nil when Connected)
+}
+
+// ResilientSubscriber keeps a subscription alive across connection drops.
+// When a receive fails it reconnects with exponential backoff and
+// re-subscribes to the same channels and patterns, while callers keep
+// reading from the one Messages channel. Messages published while it was
+// disconnected are lost - that's pub/sub.
+type ResilientSubscriber struct {
+	client   *redis.Client
+	channels []string
+	patterns []string
+
+	messages chan *redis.Message
+	events   chan ConnectionEvent
+}
+
+func NewResilientSubscriber(client *redis.Client, channels, patterns []string) *ResilientSubscriber {
+	return &ResilientSubscriber{
+		client:   client,
+		channels: channels,
+		patterns: patterns,
+		messages: make(chan *redis.Message, 100),
+		events:   make(chan ConnectionEvent, 10),
+	}
+}
+
+// Messages delivers messages from every channel and pattern. It is closed
+// when Run returns.
+func (s *ResilientSubscriber) Messages() <-chan *redis.Message { return s.messages }
+
+// Events reports disconnects and reconnects. Events are dropped if nobody
+// reads them.
+func (s *ResilientSubscriber) Events() <-chan ConnectionEvent { return s.events }
+
+// Run subscribes and keeps the subscription alive until ctx is cancelled
+func (s *ResilientSubscriber) Run(ctx context.Context) {
+	defer close(s.messages)
+
+	backoff := minReconnectBackoff
+	attempt := 0
+	for ctx.Err() == nil {
+		err := s.receive(ctx, func() {
+			// Subscribed: report it and reset the backoff
+			s.emit(ConnectionEvent{Connected: true, Attempt: attempt})
+			attempt = 0
+			backoff = minReconnectBackoff
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		attempt++
+		s.emit(ConnectionEvent{Connected: false, Attempt: attempt, Err: err})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxReconnectBackoff)
+	}
+}
+
+// receive runs one connection: subscribe, then forward messages until a
+// receive fails. onSubscribed is called once every subscription is confirmed.
+func (s *ResilientSubscriber) receive(ctx context.Context, onSubscribed func()) error {
+	ps := s.client.Subscribe(ctx)
+	defer ps.Close()
+
+	// Receive doesn't watch ctx, so closing the connection is what unblocks it
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ps.Close()
+		case <-done:
+		}
+	}()
+
+	if len(s.channels) > 0 {
+		if err := ps.Subscribe(ctx, s.channels...); err != nil {
+			return err
+		}
+	}
+	if len(s.patterns) > 0 {
+		if err := ps.PSubscribe(ctx, s.patterns...); err != nil {
+			return err
+		}
+	}
+
+	pending := len(s.channels) + len(s.patterns)
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if pending--; pending == 0 {
+				onSubscribed()
+			}
+		case *redis.Message:
+			select {
+			case s.messages <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (s *ResilientSubscriber) emit(event ConnectionEvent) {
+	select {
+	case s.events <- event:
+	default:
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HandlerFunc handles one pub/sub message
+type HandlerFunc func(channel, payload string)
+
+// Router dispatches pub/sub messages to handlers registered per channel or
+// per glob pattern. It keeps one subscription per distinct name: plain names
+// use SUBSCRIBE, names containing *, ? or [ use PSUBSCRIBE. When several
+// registrations match a message (say "user:*" and "user:123:login"), Redis
+// delivers it once per subscription, so each handler fires.
+type Router struct {
+	client *redis.Client
+
+	mu       sync.Mutex
+	handlers map[string][]HandlerFunc // Channel or pattern -> handlers
+	ps       *redis.PubSub            // Set while Run is active
+	runCtx   context.Context          // Run's ctx, for subscribing from Handle
+}
+
+func NewRouter(client *redis.Client) *Router {
+	return &Router{
+		client:   client,
+		handlers: make(map[string][]HandlerFunc),
+	}
+}
+
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// Handle registers fn for a channel or glob pattern. It can be called before
+// or while Run is active.
+func (r *Router) Handle(pattern string, fn HandlerFunc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, subscribed := r.handlers[pattern]
+	r.handlers[pattern] = append(r.handlers[pattern], fn)
+	if subscribed || r.ps == nil {
+		return nil
+	}
+	return r.subscribe(r.runCtx, r.ps, pattern)
+}
+
+// subscribe must be called with mu held
+func (r *Router) subscribe(ctx context.Context, ps *redis.PubSub, names ...string) error {
+	var channels, patterns []string
+	for _, name := range names {
+		if isPattern(name) {
+			patterns = append(patterns, name)
+		} else {
+			channels = append(channels, name)
+		}
+	}
+
+	if len(channels) > 0 {
+		if err := ps.Subscribe(ctx, channels...); err != nil {
+			return err
+		}
+	}
+	if len(patterns) > 0 {
+		return ps.PSubscribe(ctx, patterns...)
+	}
+	return nil
+}
+
+// Run subscribes to everything registered and dispatches messages, each
+// handler in its own goroutine, until ctx is cancelled. It waits for running
+// handlers before returning.
+func (r *Router) Run(ctx context.Context) error {
+	ps := r.client.Subscribe(ctx)
+	defer ps.Close()
+
+	r.mu.Lock()
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	err := r.subscribe(ctx, ps, names...)
+	if err == nil {
+		r.ps, r.runCtx = ps, ctx
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		r.mu.Lock()
+		r.ps, r.runCtx = nil, nil
+		r.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	messages := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			// pmessage carries the pattern that matched; plain message doesn't
+			key := msg.Channel
+			if msg.Pattern != "" {
+				key = msg.Pattern
+			}
+
+			r.mu.Lock()
+			handlers := r.handlers[key]
+			r.mu.Unlock()
+
+			for _, fn := range handlers {
+				wg.Add(1)
+				go func(fn HandlerFunc) {
+					defer wg.Done()
+					fn(msg.Channel, msg.Payload)
+				}(fn)
+			}
+		}
+	}
+}
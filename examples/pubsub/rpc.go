@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRequestTimeout applies when Request's ctx has no deadline
+const defaultRequestTimeout = 5 * time.Second
+
+// ErrNoResponders is returned when nobody is subscribed to the request channel
+var ErrNoResponders = errors.New("rpc: no servers listening")
+
+// rpcMessage is the envelope for both requests and replies
+type rpcMessage struct {
+	ID      string `json:"id"`                 // Correlation id, copied into the reply
+	ReplyTo string `json:"reply_to,omitempty"` // Requests only
+	Payload []byte `json:"payload,omitempty"`
+	Error   string `json:"error,omitempty"` // Replies only: the handler failed
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RPCClient sends requests over pub/sub and matches replies by correlation id.
+// All replies come back on one private channel; a reply whose id isn't
+// pending (e.g. it arrived after its request timed out) is dropped.
+type RPCClient struct {
+	client  *redis.Client
+	replyTo string
+	sub     *redis.PubSub
+	done    chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]chan rpcMessage
+}
+
+// NewRPCClient subscribes to a private reply channel. Call Close when done.
+func NewRPCClient(ctx context.Context, client *redis.Client) (*RPCClient, error) {
+	replyTo := "rpc:reply:" + newID()
+	sub := client.Subscribe(ctx, replyTo)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	c := &RPCClient{
+		client:  client,
+		replyTo: replyTo,
+		sub:     sub,
+		done:    make(chan struct{}),
+		pending: make(map[string]chan rpcMessage),
+	}
+	go c.listen()
+	return c, nil
+}
+
+func (c *RPCClient) listen() {
+	defer close(c.done)
+
+	for msg := range c.sub.Channel() {
+		var reply rpcMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &reply); err != nil {
+			log.Printf("RPC client: malformed reply: %v", err)
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[reply.ID]
+		delete(c.pending, reply.ID)
+		c.mu.Unlock()
+
+		if ok {
+			ch <- reply // Buffered, never blocks
+		}
+	}
+}
+
+// Request publishes payload on channel and waits for the reply, until ctx
+// is done (or defaultRequestTimeout if ctx has no deadline)
+func (c *RPCClient) Request(ctx context.Context, channel string, payload []byte) ([]byte, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+
+	req := rpcMessage{ID: newID(), ReplyTo: c.replyTo, Payload: payload}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register before publishing so a fast reply can't beat us
+	replyCh := make(chan rpcMessage, 1)
+	c.mu.Lock()
+	c.pending[req.ID] = replyCh
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, req.ID)
+		c.mu.Unlock()
+	}()
+
+	receivers, err := c.client.Publish(ctx, channel, data).Result()
+	if err != nil {
+		return nil, err
+	}
+	if receivers == 0 {
+		return nil, ErrNoResponders
+	}
+
+	select {
+	case reply := <-replyCh:
+		if reply.Error != "" {
+			return nil, errors.New(reply.Error)
+		}
+		return reply.Payload, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close unsubscribes from the reply channel
+func (c *RPCClient) Close() error {
+	err := c.sub.Close()
+	<-c.done
+	return err
+}
+
+// Serve answers requests on channel with handler until ctx is cancelled.
+// Each request runs in its own goroutine; a handler error is sent back to
+// the caller as the reply's error.
+func Serve(ctx context.Context, client *redis.Client, channel string, handler func(payload []byte) ([]byte, error)) error {
+	sub := client.Subscribe(ctx, channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var req rpcMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil || req.ReplyTo == "" {
+				log.Printf("RPC server %s: ignoring malformed request", channel)
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				reply := rpcMessage{ID: req.ID}
+				result, err := handler(req.Payload)
+				if err != nil {
+					reply.Error = err.Error()
+				} else {
+					reply.Payload = result
+				}
+
+				data, _ := json.Marshal(reply)
+				// Use a fresh context: the reply should go out even if we're shutting down
+				if err := client.Publish(context.WithoutCancel(ctx), req.ReplyTo, data).Err(); err != nil {
+					log.Printf("RPC server %s: reply failed: %v", channel, err)
+				}
+			}()
+		}
+	}
+}
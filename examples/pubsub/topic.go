@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Topic is a pub/sub channel carrying JSON-encoded values of type T
+type Topic[T any] struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewTopic[T any](client *redis.Client, channel string) *Topic[T] {
+	return &Topic[T]{
+		client:  client,
+		channel: channel,
+	}
+}
+
+// Publish JSON-encodes msg and sends it to every current subscriber
+func (t *Topic[T]) Publish(ctx context.Context, msg T) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return t.client.Publish(ctx, t.channel, data).Err()
+}
+
+// Subscribe returns a channel of decoded messages. The subscription is
+// confirmed before returning, so anything published afterwards is received.
+// Messages that aren't valid T are logged and skipped. The channel is closed
+// when ctx is cancelled.
+func (t *Topic[T]) Subscribe(ctx context.Context) (<-chan T, error) {
+	sub := t.client.Subscribe(ctx, t.channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var value T
+				if err := json.Unmarshal([]byte(msg.Payload), &value); err != nil {
+					log.Printf("Topic %s: skipping malformed message: %v", t.channel, err)
+					continue
+				}
+
+				select {
+				case out <- value:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
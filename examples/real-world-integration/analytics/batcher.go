@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrBatcherClosed is returned for commands queued after Close
+var ErrBatcherClosed = errors.New("batcher closed")
+
+// batchOp is one queued command and where to deliver its result
+type batchOp struct {
+	build  func(pipe redis.Pipeliner) redis.Cmder
+	result chan redis.Cmder
+}
+
+// Batcher collects commands from many goroutines and sends them to Redis in
+// one pipeline, flushing when maxSize commands are waiting or window has
+// passed since the first one. Under bursty load (e.g. Track calls from every
+// request) that turns thousands of round trips into a handful, at the cost
+// of up to window extra latency per command.
+type Batcher struct {
+	client  *redis.Client
+	maxSize int
+	window  time.Duration
+
+	ops     chan batchOp
+	mu      sync.RWMutex // Guards closed against sends racing Close
+	closed  bool
+	done    chan struct{}
+	flushes atomic.Int64
+}
+
+func NewBatcher(client *redis.Client, maxSize int, window time.Duration) *Batcher {
+	b := &Batcher{
+		client:  client,
+		maxSize: maxSize,
+		window:  window,
+		ops:     make(chan batchOp, maxSize),
+		done:    make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// Queue adds a command to the next batch. The returned channel receives the
+// command once its batch has run; check its Err() and read its value.
+func (b *Batcher) Queue(build func(pipe redis.Pipeliner) redis.Cmder) (<-chan redis.Cmder, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return nil, ErrBatcherClosed
+	}
+
+	op := batchOp{build: build, result: make(chan redis.Cmder, 1)}
+	b.ops <- op
+	return op.result, nil
+}
+
+// Incr queues INCR key and waits for its result
+func (b *Batcher) Incr(ctx context.Context, key string) (int64, error) {
+	result, err := b.Queue(func(pipe redis.Pipeliner) redis.Cmder {
+		return pipe.Incr(context.Background(), key)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case cmd := <-result:
+		return cmd.(*redis.IntCmd).Result()
+	case <-ctx.Done():
+		return 0, ctx.Err() // The command still runs with its batch
+	}
+}
+
+// Flushes returns how many pipelines have been sent
+func (b *Batcher) Flushes() int64 {
+	return b.flushes.Load()
+}
+
+// Close stops accepting commands, flushes everything already queued and
+// waits for it to finish
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.ops)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *Batcher) loop() {
+	defer close(b.done)
+
+	var pending []batchOp
+	var timer <-chan time.Time
+	for {
+		select {
+		case op, ok := <-b.ops:
+			if !ok {
+				b.flush(pending)
+				return
+			}
+			if len(pending) == 0 {
+				timer = time.After(b.window)
+			}
+			pending = append(pending, op)
+			if len(pending) < b.maxSize {
+				continue
+			}
+		case <-timer:
+		}
+
+		b.flush(pending)
+		pending, timer = nil, nil
+	}
+}
+
+// flush sends ops in one pipeline and hands each caller its command
+func (b *Batcher) flush(ops []batchOp) {
+	if len(ops) == 0 {
+		return
+	}
+
+	cmds := make([]redis.Cmder, len(ops))
+	// Commands from many callers share this pipeline, so no single
+	// caller's ctx applies; each command carries its own error
+	b.client.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		for i, op := range ops {
+			cmds[i] = op.build(pipe)
+		}
+		return nil
+	})
+	b.flushes.Add(1)
+
+	for i, op := range ops {
+		op.result <- cmds[i]
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	dauRetention = 90 * 24 * time.Hour // Daily bitmaps kept ~3 months
+	dauOpTTL     = time.Minute         // BITOP results are scratch keys
+)
+
+// DAU tracks daily active users with one bitmap per day: bit N is set when
+// user N was active. 1M users fit in 125KB per day, and BITOP combines days
+// in a single command. Keys:
+//
+//	dau:<YYYY-MM-DD>   bitmap   users active that day
+//
+// User IDs must be small, dense integers; a sparse ID like 10^12 would
+// allocate a huge bitmap.
+type DAU struct {
+	client *redis.Client
+}
+
+func NewDAU(client *redis.Client) *DAU {
+	return &DAU{client: client}
+}
+
+func dauKey(day time.Time) string {
+	return "dau:" + day.UTC().Format("2006-01-02")
+}
+
+// MarkActive records that userID was active on day
+func (d *DAU) MarkActive(ctx context.Context, userID int64, day time.Time) error {
+	key := dauKey(day)
+	_, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetBit(ctx, key, userID, 1)
+		pipe.Expire(ctx, key, dauRetention)
+		return nil
+	})
+	return err
+}
+
+// CountActive returns how many users were active on day
+func (d *DAU) CountActive(ctx context.Context, day time.Time) (int64, error) {
+	return d.client.BitCount(ctx, dauKey(day), nil).Result()
+}
+
+// ActiveOnBoth returns how many users were active on both days (retention)
+func (d *DAU) ActiveOnBoth(ctx context.Context, day1, day2 time.Time) (int64, error) {
+	dest := fmt.Sprintf("dau:and:%s:%s", day1.UTC().Format("2006-01-02"), day2.UTC().Format("2006-01-02"))
+	return d.combine(ctx, "AND", dest, dauKey(day1), dauKey(day2))
+}
+
+// ActiveInRange returns how many users were active on at least one day
+// from..to inclusive (reach)
+func (d *DAU) ActiveInRange(ctx context.Context, from, to time.Time) (int64, error) {
+	var keys []string
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to.UTC()); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, dauKey(day))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	dest := fmt.Sprintf("dau:or:%s:%s", from.UTC().Format("2006-01-02"), to.UTC().Format("2006-01-02"))
+	return d.combine(ctx, "OR", dest, keys...)
+}
+
+// combine runs BITOP op over keys into dest and counts the result
+func (d *DAU) combine(ctx context.Context, op, dest string, keys ...string) (int64, error) {
+	cmds, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if op == "AND" {
+			pipe.BitOpAnd(ctx, dest, keys...)
+		} else {
+			pipe.BitOpOr(ctx, dest, keys...)
+		}
+		pipe.Expire(ctx, dest, dauOpTTL)
+		pipe.BitCount(ctx, dest, nil)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cmds[2].(*redis.IntCmd).Val(), nil
+}
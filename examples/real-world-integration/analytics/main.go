@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Analytics Example                             ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1UniqueVisitors(client)
+	demo2DailyActiveUsers(client)
+	demo3Batching(client)
+}
+
+// Demo 1: Unique Visitors (HyperLogLog)
+func demo1UniqueVisitors(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Unique Visitors (HyperLogLog)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	analytics := NewAnalytics(client)
+	page := "/pricing"
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+	client.Del(ctx, dailyKey(page, today), dailyKey(page, yesterday))
+
+	// Yesterday: visitors 0-999, each visiting 3 times
+	for visit := 0; visit < 3; visit++ {
+		for v := 0; v < 1000; v++ {
+			analytics.TrackAt(ctx, page, fmt.Sprintf("visitor-%d", v), yesterday)
+		}
+	}
+	// Today: visitors 500-1499 (half of them came back)
+	for v := 500; v < 1500; v++ {
+		analytics.TrackAt(ctx, page, fmt.Sprintf("visitor-%d", v), today)
+	}
+
+	y, _ := analytics.UniqueVisitors(ctx, page, yesterday)
+	t, _ := analytics.UniqueVisitors(ctx, page, today)
+	both, _ := analytics.UniqueOverRange(ctx, page, yesterday, today)
+	fmt.Printf("  Yesterday: ~%d unique (3000 visits, 1000 real)\n", y)
+	fmt.Printf("  Today:     ~%d unique (1000 real)\n", t)
+	fmt.Printf("  Both days: ~%d unique (1500 real, overlap counted once)\n", both)
+	fmt.Println("  Memory: ~12KB per HLL key regardless of visitor count")
+	fmt.Println()
+}
+
+// Demo 2: Daily Active Users (Bitmaps)
+func demo2DailyActiveUsers(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 2: Daily Active Users (Bitmaps)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	dau := NewDAU(client)
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+	client.Del(ctx, dauKey(today), dauKey(yesterday))
+
+	// Yesterday: users 1-100; today: users 61-150
+	for id := int64(1); id <= 100; id++ {
+		dau.MarkActive(ctx, id, yesterday)
+	}
+	for id := int64(61); id <= 150; id++ {
+		dau.MarkActive(ctx, id, today)
+	}
+
+	y, _ := dau.CountActive(ctx, yesterday)
+	t, _ := dau.CountActive(ctx, today)
+	retained, _ := dau.ActiveOnBoth(ctx, yesterday, today)
+	reach, _ := dau.ActiveInRange(ctx, yesterday, today)
+	fmt.Printf("  Yesterday: %d active\n", y)
+	fmt.Printf("  Today:     %d active\n", t)
+	fmt.Printf("  Retained (BITOP AND): %d (%.0f%% of yesterday)\n", retained, float64(retained)/float64(y)*100)
+	fmt.Printf("  Reach    (BITOP OR):  %d\n", reach)
+	fmt.Println("  Counts are exact, unlike HyperLogLog")
+	fmt.Println()
+}
+
+// Demo 3: Auto-Batching Pipeline
+func demo3Batching(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 3: Auto-Batching Pipeline")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Del(ctx, "pageviews:/pricing")
+
+	batcher := NewBatcher(client, 100, 5*time.Millisecond)
+
+	// 1000 concurrent requests each count a page view
+	start := time.Now()
+	results := make([]int64, 1000)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = batcher.Incr(ctx, "pageviews:/pricing")
+		}(i)
+	}
+	wg.Wait()
+	batcher.Close()
+
+	// Every caller got its own INCR result, so all of 1..1000 appear once
+	seen := make(map[int64]bool)
+	for _, n := range results {
+		seen[n] = true
+	}
+	fmt.Printf("  1000 INCRs in %d pipelines (%v)\n", batcher.Flushes(), time.Since(start).Round(time.Millisecond))
+	fmt.Printf("  Distinct results: %d, final count: %s\n", len(seen), client.Get(ctx, "pageviews:/pricing").Val())
+	fmt.Println()
+}
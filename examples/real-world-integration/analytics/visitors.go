@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	dailyRetention   = 90 * 24 * time.Hour  // Daily HLLs kept ~3 months
+	monthlyRetention = 400 * 24 * time.Hour // Monthly HLLs kept ~13 months
+)
+
+// Analytics counts unique visitors per page with HyperLogLog: ~12KB per key
+// no matter how many visitors, with ~0.81% standard error. Keys:
+//
+//	visitors:<page>:<YYYY-MM-DD>   HLL   visitors that day
+//	visitors:<page>:<YYYY-MM>      HLL   visitors that month
+type Analytics struct {
+	client *redis.Client
+}
+
+func NewAnalytics(client *redis.Client) *Analytics {
+	return &Analytics{client: client}
+}
+
+func dailyKey(page string, day time.Time) string {
+	return "visitors:" + page + ":" + day.UTC().Format("2006-01-02")
+}
+
+func monthlyKey(page string, month time.Time) string {
+	return "visitors:" + page + ":" + month.UTC().Format("2006-01")
+}
+
+// Track records a visit; repeat visits by the same visitor don't change counts
+func (a *Analytics) Track(ctx context.Context, page, visitorID string) error {
+	return a.TrackAt(ctx, page, visitorID, time.Now())
+}
+
+// TrackAt records a visit at a given time (e.g. when backfilling)
+func (a *Analytics) TrackAt(ctx context.Context, page, visitorID string, at time.Time) error {
+	daily, monthly := dailyKey(page, at), monthlyKey(page, at)
+
+	_, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFAdd(ctx, daily, visitorID)
+		pipe.Expire(ctx, daily, dailyRetention)
+		pipe.PFAdd(ctx, monthly, visitorID)
+		pipe.Expire(ctx, monthly, monthlyRetention)
+		return nil
+	})
+	return err
+}
+
+// UniqueVisitors estimates unique visitors to page on day
+func (a *Analytics) UniqueVisitors(ctx context.Context, page string, day time.Time) (int64, error) {
+	return a.client.PFCount(ctx, dailyKey(page, day)).Result()
+}
+
+// UniqueVisitorsMonth estimates unique visitors to page in month
+func (a *Analytics) UniqueVisitorsMonth(ctx context.Context, page string, month time.Time) (int64, error) {
+	return a.client.PFCount(ctx, monthlyKey(page, month)).Result()
+}
+
+// UniqueOverRange estimates unique visitors across every day from..to
+// (inclusive). A visitor seen on several days counts once: PFCOUNT with
+// several keys merges the daily HLLs into a temporary one and counts that,
+// so nothing is stored and today's visits are always included.
+func (a *Analytics) UniqueOverRange(ctx context.Context, page string, from, to time.Time) (int64, error) {
+	var keys []string
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to.UTC()); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, dailyKey(page, day))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	return a.client.PFCount(ctx, keys...).Result()
+}
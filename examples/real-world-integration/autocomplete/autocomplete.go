@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Autocomplete suggests terms by prefix from a single sorted set.
+// Every member has score 0, so Redis orders them lexicographically and
+// ZRANGEBYLEX can slice out everything starting with a prefix.
+//
+// Members are stored as "<normalized>\x00<display>" (e.g. "new york\x00New York"):
+// the normalized half is what prefixes match against, the display half is
+// what Suggest returns.
+type Autocomplete struct {
+	client *redis.Client
+	key    string
+}
+
+func NewAutocomplete(client *redis.Client, key string) *Autocomplete {
+	return &Autocomplete{client: client, key: key}
+}
+
+// separator splits the normalized and display halves of a member. \x00 sorts
+// before any printable byte, so "new" still comes before "new york".
+const separator = "\x00"
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Add indexes terms for suggestion; re-adding a term is a no-op
+func (a *Autocomplete) Add(ctx context.Context, terms ...string) error {
+	members := make([]redis.Z, 0, len(terms))
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		members = append(members, redis.Z{Score: 0, Member: normalize(term) + separator + term})
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return a.client.ZAdd(ctx, a.key, members...).Err()
+}
+
+// Suggest returns up to limit terms starting with prefix (case-insensitive),
+// in alphabetical order of their normalized form
+func (a *Autocomplete) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	prefix = normalize(prefix)
+	members, err := a.client.ZRangeByLex(ctx, a.key, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff", // \xff sorts after every byte in UTF-8 text
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]string, 0, len(members))
+	for _, m := range members {
+		if _, display, ok := strings.Cut(m, separator); ok {
+			suggestions = append(suggestions, display)
+		}
+	}
+	return suggestions, nil
+}
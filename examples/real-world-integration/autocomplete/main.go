@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Autocomplete Example                          ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1Suggest(client)
+}
+
+// Demo 1: Prefix Suggestions (ZRANGEBYLEX)
+func demo1Suggest(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Prefix Suggestions (ZRANGEBYLEX)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Del(ctx, "autocomplete:cities")
+
+	cities := NewAutocomplete(client, "autocomplete:cities")
+	cities.Add(ctx, "New York", "New Orleans", "Newark", "Nashville", "Boston", "Berlin", "Bern", "new delhi")
+
+	for _, prefix := range []string{"new", "NEW Y", "ber", "b", "xyz"} {
+		suggestions, err := cities.Suggest(ctx, prefix, 5)
+		if err != nil {
+			log.Printf("Suggest failed: %v", err)
+			continue
+		}
+		fmt.Printf("  %-6q → %v\n", prefix, suggestions)
+	}
+	fmt.Println()
+	fmt.Println("  All members share score 0, so the set is ordered alphabetically")
+	fmt.Println("  and one O(log N + M) range query answers each prefix.")
+	fmt.Println()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Deduper answers "have I seen this before?" for a stream of items (event
+// IDs, URLs) in fixed memory, using a Bloom filter. A "no" is always right; a
+// "yes" is wrong with probability FalsePositiveRate(), once expectedItems
+// items have been added.
+//
+// If the server has the RedisBloom module, the filter is a native BF.*
+// filter. Otherwise it is a plain bitmap at key, driven by SETBIT/GETBIT in a
+// Lua script so check-and-record is atomic.
+type Deduper struct {
+	client *redis.Client
+	key    string
+	fpRate float64
+	native bool  // BF.* available
+	bits   int64 // bitmap size (m), fallback only
+	hashes int   // bits set per item (k), fallback only
+}
+
+// NewDeduper sizes a filter for expectedItems at the given false-positive
+// rate (e.g. 0.01 for 1%). expectedItems must be positive and fpRate
+// strictly between 0 and 1, or the fallback sizing divides by zero.
+func NewDeduper(ctx context.Context, client *redis.Client, key string, expectedItems int64, fpRate float64) (*Deduper, error) {
+	if expectedItems <= 0 {
+		return nil, fmt.Errorf("dedup: expectedItems must be positive, got %d", expectedItems)
+	}
+	if !(fpRate > 0 && fpRate < 1) {
+		return nil, fmt.Errorf("dedup: fpRate must be between 0 and 1, got %g", fpRate)
+	}
+
+	d := &Deduper{client: client, key: key, fpRate: fpRate}
+
+	err := client.BFReserve(ctx, key, fpRate, expectedItems).Err()
+	switch {
+	case err == nil || isItemExists(err):
+		d.native = true
+		return d, nil
+	case !isUnknownCommand(err):
+		return nil, err
+	}
+
+	// Optimal sizing: m = -n·ln(p) / ln(2)², k = (m/n)·ln(2)
+	n := float64(expectedItems)
+	d.bits = int64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	d.hashes = max(1, int(math.Round(float64(d.bits)/n*math.Ln2)))
+	return d, nil
+}
+
+// FalsePositiveRate is the configured chance that Seen wrongly returns true
+func (d *Deduper) FalsePositiveRate() float64 {
+	return d.fpRate
+}
+
+// Seen reports whether item was probably seen before, and records it
+func (d *Deduper) Seen(ctx context.Context, item string) (bool, error) {
+	if d.native {
+		added, err := d.client.BFAdd(ctx, d.key, item).Result()
+		return !added, err
+	}
+
+	script := `
+		local seen = 1
+		for i = 1, #ARGV do
+			if redis.call('GETBIT', KEYS[1], ARGV[i]) == 0 then
+				seen = 0
+				redis.call('SETBIT', KEYS[1], ARGV[i], 1)
+			end
+		end
+		return seen
+	`
+
+	offsets := d.offsets(item)
+	args := make([]interface{}, len(offsets))
+	for i, o := range offsets {
+		args[i] = o
+	}
+	seen, err := d.client.Eval(ctx, script, []string{d.key}, args...).Int()
+	return seen == 1, err
+}
+
+// offsets returns the k bit positions for item, using double hashing
+// (h1 + i·h2) so only two real hashes are computed
+func (d *Deduper) offsets(item string) []int64 {
+	h1 := fnv.New64a()
+	h1.Write([]byte(item))
+	h2 := fnv.New64()
+	h2.Write([]byte(item))
+	a, b := h1.Sum64(), h2.Sum64()|1
+
+	offsets := make([]int64, d.hashes)
+	for i := range offsets {
+		offsets[i] = int64((a + uint64(i)*b) % uint64(d.bits))
+	}
+	return offsets
+}
+
+func isUnknownCommand(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "ERR unknown command")
+}
+
+// isItemExists reports BF.RESERVE on a filter that already exists
+func isItemExists(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.Contains(redisErr.Error(), "item exists")
+}
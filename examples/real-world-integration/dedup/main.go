@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Bloom Filter Dedup Example                    ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1Dedup(client)
+	demo2TimedSet(client)
+}
+
+// Demo 1: Deduplicating Events (Bloom Filter)
+func demo1Dedup(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Deduplicating Events (Bloom Filter)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Del(ctx, "dedup:events")
+
+	deduper, err := NewDeduper(ctx, client, "dedup:events", 10000, 0.01)
+	if err != nil {
+		log.Printf("NewDeduper failed: %v", err)
+		return
+	}
+	if deduper.native {
+		fmt.Println("  Using RedisBloom (BF.ADD)")
+	} else {
+		fmt.Printf("  RedisBloom not loaded: using a %d-bit bitmap with %d hashes\n", deduper.bits, deduper.hashes)
+	}
+	fmt.Printf("  Configured false-positive rate: %.1f%%\n", deduper.FalsePositiveRate()*100)
+	fmt.Println()
+
+	for _, id := range []string{"evt-1", "evt-2", "evt-1", "evt-3", "evt-2"} {
+		seen, err := deduper.Seen(ctx, id)
+		if err != nil {
+			log.Printf("Seen failed: %v", err)
+			return
+		}
+		if seen {
+			fmt.Printf("  %s → 🔁 duplicate, skipped\n", id)
+		} else {
+			fmt.Printf("  %s → ✅ new, processed\n", id)
+		}
+	}
+	fmt.Println()
+
+	// Fill to capacity, then measure how often brand-new items look seen
+	for i := 0; i < 10000; i++ {
+		deduper.Seen(ctx, fmt.Sprintf("fill-%d", i))
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if seen, _ := deduper.Seen(ctx, fmt.Sprintf("fresh-%d", i)); seen {
+			falsePositives++
+		}
+	}
+	fmt.Printf("  At capacity: %d of 1000 new items wrongly flagged (~%.1f%%)\n",
+		falsePositives, float64(falsePositives)/10)
+	fmt.Printf("  Memory: %d bytes for 10,000 items\n", client.MemoryUsage(ctx, "dedup:events").Val())
+	fmt.Println()
+}
+
+// Demo 2: Short Dedup Window (TimedSet)
+func demo2TimedSet(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 2: Short Dedup Window (TimedSet)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Del(ctx, "dedup:notifications")
+	recent := NewTimedSet(client, "dedup:notifications")
+
+	// Don't send the same notification twice within 1 second
+	notify := func(id string) {
+		seen, err := recent.Contains(ctx, id)
+		if err != nil {
+			log.Printf("Contains failed: %v", err)
+			return
+		}
+		if seen {
+			fmt.Printf("  %s → 🔁 sent recently, suppressed\n", id)
+			return
+		}
+		recent.Add(ctx, id, time.Second)
+		fmt.Printf("  %s → 📨 sent\n", id)
+	}
+
+	notify("order-42-shipped")
+	notify("order-42-shipped")
+	fmt.Println("  ...1.2 seconds later...")
+	time.Sleep(1200 * time.Millisecond)
+	notify("order-42-shipped")
+	fmt.Println()
+	fmt.Println("  Each member has its own expiry (its score), unlike a SET")
+	fmt.Println("  where EXPIRE can only apply to the whole key.")
+	fmt.Println()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TimedSet is a set whose members expire individually, which plain Redis
+// sets can't do. Members live in a sorted set scored by their expiry time
+// (unix ms):
+//
+//	key  →  { <member>: <expires at ms>, ... }
+//
+// Contains ignores members past their expiry, and every Add first purges
+// them with ZREMRANGEBYSCORE, so the set never grows beyond the members
+// added within the longest TTL.
+type TimedSet struct {
+	client *redis.Client
+	key    string
+}
+
+func NewTimedSet(client *redis.Client, key string) *TimedSet {
+	return &TimedSet{client: client, key: key}
+}
+
+// Add inserts member (or resets its expiry) for ttl
+func (s *TimedSet) Add(ctx context.Context, member string, ttl time.Duration) error {
+	now := time.Now()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, s.key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+		pipe.ZAdd(ctx, s.key, redis.Z{Score: float64(now.Add(ttl).UnixMilli()), Member: member})
+		return nil
+	})
+	return err
+}
+
+// Contains reports whether member was added and hasn't expired yet
+func (s *TimedSet) Contains(ctx context.Context, member string) (bool, error) {
+	expiresAt, err := s.client.ZScore(ctx, s.key, member).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return int64(expiresAt) > time.Now().UnixMilli(), nil
+}
+
+// Purge removes every expired member and returns how many were removed.
+// Add already purges; call this from a ticker if adds are rare.
+func (s *TimedSet) Purge(ctx context.Context) (int64, error) {
+	return s.client.ZRemRangeByScore(ctx, s.key, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10)).Result()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	flagsKey     = "features"         // HASH  flag -> "1" / "0"
+	flagsChannel = "features:changed" // PUBLISH "<flag>=1" / "<flag>=0" on every change
+)
+
+// FlagStore serves feature flags from an in-memory snapshot, so IsEnabled
+// never touches the network. The snapshot is updated immediately from a
+// pub/sub channel whenever a flag changes, and fully reloaded every
+// refreshInterval in case a message was missed (pub/sub doesn't retry).
+type FlagStore struct {
+	client *redis.Client
+
+	mu    sync.RWMutex
+	flags map[string]bool
+
+	sub    *redis.PubSub
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewFlagStore loads the current flags and starts listening for changes.
+// refreshInterval must be positive. Call Close to stop.
+func NewFlagStore(ctx context.Context, client *redis.Client, refreshInterval time.Duration) (*FlagStore, error) {
+	if refreshInterval <= 0 {
+		return nil, fmt.Errorf("feature flags: refresh interval must be positive, got %v", refreshInterval)
+	}
+
+	// Subscribe before loading, so a change made in between isn't missed
+	sub := client.Subscribe(ctx, flagsChannel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	s := &FlagStore{client: client, sub: sub}
+	if err := s.reload(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.listen()
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.refreshLoop(runCtx, refreshInterval)
+	}()
+	return s, nil
+}
+
+// IsEnabled reports the flag from the local snapshot; unknown flags are off
+func (s *FlagStore) IsEnabled(flag string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.flags[flag]
+}
+
+// SetFlag turns a flag on or off for every FlagStore
+func (s *FlagStore) SetFlag(ctx context.Context, flag string, on bool) error {
+	value := "0"
+	if on {
+		value = "1"
+	}
+
+	// HSET + PUBLISH together, so the change is never stored but unannounced
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, flagsKey, flag, value)
+		pipe.Publish(ctx, flagsChannel, flag+"="+value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.set(flag, on)
+	return nil
+}
+
+func (s *FlagStore) set(flag string, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[flag] = on
+}
+
+// reload replaces the snapshot with the hash's current contents
+func (s *FlagStore) reload(ctx context.Context) error {
+	values, err := s.client.HGetAll(ctx, flagsKey).Result()
+	if err != nil {
+		return err
+	}
+
+	flags := make(map[string]bool, len(values))
+	for flag, value := range values {
+		flags[flag] = value == "1"
+	}
+
+	s.mu.Lock()
+	s.flags = flags
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *FlagStore) listen() {
+	for msg := range s.sub.Channel() {
+		flag, value, ok := strings.Cut(msg.Payload, "=")
+		if !ok {
+			continue
+		}
+		s.set(flag, value == "1")
+	}
+}
+
+func (s *FlagStore) refreshLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.reload(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Feature flags refresh error: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops the subscriber and the refresh loop
+func (s *FlagStore) Close() error {
+	s.cancel()
+	err := s.sub.Close()
+	s.wg.Wait()
+	return err
+}
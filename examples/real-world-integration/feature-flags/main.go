@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Feature Flags Example                         ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	client.Del(ctx, flagsKey)
+
+	// Two app servers, each with its own in-memory snapshot
+	server1, err := NewFlagStore(ctx, client, 30*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server1.Close()
+	server2, err := NewFlagStore(ctx, client, 30*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server2.Close()
+
+	fmt.Printf("Before: new-checkout on server 2? %v\n", server2.IsEnabled("new-checkout"))
+
+	// Flip the flag on server 1...
+	server1.SetFlag(ctx, "new-checkout", true)
+	fmt.Println("Server 1: SetFlag(new-checkout, on)")
+
+	// ...server 2 hears about it over pub/sub, without polling
+	time.Sleep(50 * time.Millisecond)
+	fmt.Printf("After:  new-checkout on server 2? %v\n", server2.IsEnabled("new-checkout"))
+	fmt.Println()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInFlight means another request with the same idempotency key is still
+// running. Callers usually reply 409 Conflict and let the client retry.
+var ErrInFlight = errors.New("idempotency: request with this key is in flight")
+
+const (
+	pendingMarker = "\x00pending" // Claimed, result not recorded yet
+	resultPrefix  = "\x00done:"   // Followed by the recorded response
+)
+
+// Idempotency makes retried requests safe: the first request with a key runs
+// the operation, and repeats get the stored response instead of running it
+// again (e.g. charging a card twice). Keys:
+//
+//	idem:<key>   string   pendingMarker while running, resultPrefix+response after
+type Idempotency struct {
+	client *redis.Client
+}
+
+func NewIdempotency(client *redis.Client) *Idempotency {
+	return &Idempotency{client: client}
+}
+
+func idemKey(key string) string {
+	return "idem:" + key
+}
+
+// Begin claims key for ttl. firstTime is true when the caller should run the
+// operation and then call Complete (or Abort if it fails). Otherwise the
+// stored response is returned, or ErrInFlight if the first request hasn't
+// finished yet.
+func (i *Idempotency) Begin(ctx context.Context, key string, ttl time.Duration) (firstTime bool, cachedResult []byte, err error) {
+	for {
+		claimed, err := i.client.SetNX(ctx, idemKey(key), pendingMarker, ttl).Result()
+		if err != nil {
+			return false, nil, err
+		}
+		if claimed {
+			return true, nil, nil
+		}
+
+		val, err := i.client.Get(ctx, idemKey(key)).Result()
+		if errors.Is(err, redis.Nil) {
+			continue // Expired or aborted between SETNX and GET - try to claim again
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		if val == pendingMarker {
+			return false, nil, ErrInFlight
+		}
+		return false, []byte(strings.TrimPrefix(val, resultPrefix)), nil
+	}
+}
+
+// Complete records the response for key, keeping the TTL set by Begin
+func (i *Idempotency) Complete(ctx context.Context, key string, result []byte) error {
+	return i.client.SetArgs(ctx, idemKey(key), resultPrefix+string(result), redis.SetArgs{
+		Mode:    "XX", // Only if Begin's claim still exists
+		KeepTTL: true,
+	}).Err()
+}
+
+// Abort releases a claim whose operation failed, so a retry can run it again
+func (i *Idempotency) Abort(ctx context.Context, key string) error {
+	script := `
+		if redis.call('GET', KEYS[1]) == ARGV[1] then
+			return redis.call('DEL', KEYS[1])
+		end
+		return 0
+	`
+	return i.client.Eval(ctx, script, []string{idemKey(key)}, pendingMarker).Err()
+}
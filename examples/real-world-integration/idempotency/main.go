@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Idempotency Keys Example                      ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1RetriedPayment(client)
+}
+
+// Demo 1: Retried Payment Requests
+func demo1RetriedPayment(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Retried Payment Requests")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	idem := NewIdempotency(client)
+	client.Del(ctx, idemKey("pay-abc123"))
+
+	var charges atomic.Int32
+	pay := func(attempt int) {
+		first, cached, err := idem.Begin(ctx, "pay-abc123", 24*time.Hour)
+		switch {
+		case errors.Is(err, ErrInFlight):
+			fmt.Printf("  Attempt %d: ⏳ 409 - still processing, retry later\n", attempt)
+		case err != nil:
+			fmt.Printf("  Attempt %d: ❌ %v\n", attempt, err)
+		case first:
+			time.Sleep(200 * time.Millisecond) // Call the payment provider
+			charges.Add(1)
+			receipt := []byte(`{"charged":"$42.00","receipt":"r-001"}`)
+			idem.Complete(ctx, "pay-abc123", receipt)
+			fmt.Printf("  Attempt %d: 💳 charged, response %s\n", attempt, receipt)
+		default:
+			fmt.Printf("  Attempt %d: ♻️  replayed stored response %s\n", attempt, cached)
+		}
+	}
+
+	// A double-click: two requests race while the first is still charging
+	var wg sync.WaitGroup
+	for attempt := 1; attempt <= 2; attempt++ {
+		wg.Add(1)
+		go func(attempt int) {
+			defer wg.Done()
+			pay(attempt)
+		}(attempt)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+
+	// The client times out and retries later
+	pay(3)
+
+	fmt.Printf("\n  Card charged %d time(s) across 3 attempts\n", charges.Load())
+	fmt.Println()
+}
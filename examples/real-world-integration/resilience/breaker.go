@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCircuitOpen is returned without calling Redis while the breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open: redis unavailable")
+
+// BreakerState is the state of a BreakerClient
+type BreakerState int
+
+const (
+	// Closed: commands go through, consecutive failures are counted
+	Closed BreakerState = iota
+	// Open: commands fail fast with ErrCircuitOpen until the cooldown ends
+	Open
+	// HalfOpen: one trial command is let through to test recovery
+	HalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerClient wraps a Redis client with a circuit breaker. When Redis is
+// down, every command would otherwise wait for a dial timeout; after
+// threshold consecutive failures the breaker opens and commands fail
+// immediately for cooldown, then one trial command decides whether to close
+// it again.
+//
+// Only connection-level failures count. Redis replies like redis.Nil or
+// WRONGTYPE mean the server is up, so they never trip the breaker.
+type BreakerClient struct {
+	client    *redis.Client
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+func NewBreakerClient(client *redis.Client, threshold int, cooldown time.Duration) *BreakerClient {
+	return &BreakerClient{client: client, threshold: threshold, cooldown: cooldown}
+}
+
+// State returns the current breaker state
+func (b *BreakerClient) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Do runs op through the breaker. If the breaker is open or op fails with a
+// connection error, fallback (if non-nil) is called with the error and its
+// result is returned instead - e.g. serve from a local cache or a default.
+func (b *BreakerClient) Do(ctx context.Context, op func(ctx context.Context, c *redis.Client) error, fallback func(err error) error) error {
+	if !b.allow() {
+		return b.fail(ErrCircuitOpen, fallback)
+	}
+
+	err := op(ctx, b.client)
+	b.record(err)
+	if err != nil && isConnectionError(err) {
+		return b.fail(err, fallback)
+	}
+	return err
+}
+
+// Get is a convenience wrapper for GET through the breaker
+func (b *BreakerClient) Get(ctx context.Context, key string, fallback func(err error) (string, error)) (string, error) {
+	var val string
+	err := b.Do(ctx, func(ctx context.Context, c *redis.Client) error {
+		var err error
+		val, err = c.Get(ctx, key).Result()
+		return err
+	}, nil)
+	if err != nil && fallback != nil && (errors.Is(err, ErrCircuitOpen) || isConnectionError(err)) {
+		return fallback(err)
+	}
+	return val, err
+}
+
+func (b *BreakerClient) fail(err error, fallback func(error) error) error {
+	if fallback != nil {
+		return fallback(err)
+	}
+	return err
+}
+
+// allow decides whether a command may go to Redis
+func (b *BreakerClient) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return true
+	case Open:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = HalfOpen // This caller is the trial
+		return true
+	default:
+		return false // A trial is already in flight
+	}
+}
+
+// record updates the breaker with the outcome of a command
+func (b *BreakerClient) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up, which says nothing about Redis
+		if b.state == HalfOpen {
+			b.state = Open
+		}
+		return
+	}
+	if err != nil && isConnectionError(err) {
+		b.failures++
+		if b.state == HalfOpen || b.failures >= b.threshold {
+			b.state = Open
+			b.openedAt = time.Now()
+		}
+		return
+	}
+	b.state = Closed
+	b.failures = 0
+}
+
+// isConnectionError reports whether err means Redis couldn't be reached, as
+// opposed to a reply from the server (redis.Nil, WRONGTYPE, ...)
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Resilience Example                            ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1CircuitBreaker(client)
+	demo2Retry(client)
+}
+
+// Demo 1: Circuit Breaker
+func demo1CircuitBreaker(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Circuit Breaker")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Set(ctx, "price:widget", "$9.99", 0)
+	breaker := NewBreakerClient(client, 3, time.Second)
+
+	// Simulate an outage by failing the command before it reaches Redis
+	redisDown := false
+	getPrice := func(ctx context.Context, c *redis.Client) error {
+		if redisDown {
+			return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}
+		return c.Get(ctx, "price:widget").Err()
+	}
+	request := func(i int) {
+		fmt.Printf("  Request %d [%-9s]: ", i, breaker.State())
+		err := breaker.Do(ctx, getPrice, func(err error) error {
+			fmt.Printf("⚠️  stale price from local cache (%v)\n", err)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else if !redisDown {
+			fmt.Println("✅ fresh price from Redis")
+		}
+	}
+
+	request(1)
+	fmt.Println("  💥 Redis goes down")
+	redisDown = true
+	for i := 2; i <= 6; i++ {
+		request(i)
+	}
+	fmt.Println("  🔧 Redis recovers, waiting out the 1s cooldown...")
+	redisDown = false
+	time.Sleep(1100 * time.Millisecond)
+	request(7)
+	request(8)
+	fmt.Println()
+	fmt.Println("  After 3 failures the breaker opens: requests 5-6 never touch")
+	fmt.Println("  Redis. After the cooldown, one trial succeeds and it closes.")
+	fmt.Println()
+}
+
+// Demo 2: Retry with Backoff
+func demo2Retry(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 2: Retry with Backoff")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+	client.Set(ctx, "config:mode", "fast", 0)
+
+	// Transient: the first two attempts hit a replica that is still loading
+	attempts := 0
+	start := time.Now()
+	err := WithRetry(ctx, func() error {
+		attempts++
+		if attempts <= 2 {
+			fmt.Printf("  attempt %d at %3dms: LOADING\n", attempts, time.Since(start).Milliseconds())
+			return serverReply("LOADING Redis is loading the dataset in memory")
+		}
+		fmt.Printf("  attempt %d at %3dms: ok\n", attempts, time.Since(start).Milliseconds())
+		return client.Get(ctx, "config:mode").Err()
+	}, DefaultRetryPolicy)
+	fmt.Printf("  → err=%v after %d attempts\n\n", err, attempts)
+
+	// Logical: LPUSH on a string key fails the same way every time
+	attempts = 0
+	err = WithRetry(ctx, func() error {
+		attempts++
+		return client.LPush(ctx, "config:mode", "x").Err()
+	}, DefaultRetryPolicy)
+	fmt.Printf("  WRONGTYPE → %d attempt, not retried (%v)\n", attempts, err)
+	fmt.Println()
+}
+
+// serverReply fakes an error reply from the server (it satisfies redis.Error)
+type serverReply string
+
+func (e serverReply) Error() string { return string(e) }
+func (e serverReply) RedisError()   {}
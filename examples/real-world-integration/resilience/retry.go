@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RetryPolicy controls WithRetry
+type RetryPolicy struct {
+	MaxAttempts int           // Total tries, including the first
+	BaseDelay   time.Duration // Delay before the first retry; doubles each time
+	MaxDelay    time.Duration // Cap on the delay between retries (0 = uncapped)
+}
+
+// DefaultRetryPolicy suits most interactive requests: 4 tries within ~1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// WithRetry runs op, retrying transient Redis errors (connection refused,
+// timeouts, LOADING...) with exponential backoff and jitter. Logical errors
+// like WRONGTYPE or redis.Nil are returned at once: retrying can't fix them.
+// A policy with a negative delay is rejected before op runs.
+func WithRetry(ctx context.Context, op func() error, policy RetryPolicy) error {
+	if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+		return fmt.Errorf("retry: negative delay in policy %+v", policy)
+	}
+
+	delay := policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !isTransient(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		// Equal jitter: half the delay fixed, half random, so clients
+		// that failed together don't all retry together
+		sleep := delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+
+		if delay <= math.MaxInt64/2 {
+			delay *= 2 // Stop doubling before it overflows when uncapped
+		}
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// isTransient reports whether err might succeed if retried
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false // The caller gave up
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// Server replies: only these error codes mean "try again later". Compare
+	// the whole first word - BUSYGROUP and BUSYKEY are permanent, unlike BUSY.
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		code, _, _ := strings.Cut(redisErr.Error(), " ")
+		switch code {
+		case "LOADING", "BUSY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN":
+			return true
+		}
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
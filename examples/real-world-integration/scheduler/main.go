@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Task Scheduler Example                        ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	demo1TwoPollers(client)
+}
+
+// Demo 1: Recurring and One-Shot Tasks, Two Pollers
+func demo1TwoPollers(client *redis.Client) {
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" Demo 1: Recurring and One-Shot Tasks, Two Pollers")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2200*time.Millisecond)
+	defer cancel()
+
+	sched := NewScheduler(client, "demo")
+	client.Del(ctx, sched.due, sched.tasks, sched.intervals)
+
+	sched.ScheduleEvery(ctx, "heartbeat", "ping", 500*time.Millisecond)
+	sched.ScheduleOnce(ctx, "report", "send daily report", time.Now().Add(time.Second))
+
+	start := time.Now()
+	var mu sync.Mutex
+	runs := map[string]int{}
+
+	var wg sync.WaitGroup
+	for _, poller := range []string{"poller-A", "poller-B"} {
+		wg.Add(1)
+		go func(poller string) {
+			defer wg.Done()
+			sched.Run(ctx, 50*time.Millisecond, func(ctx context.Context, task Task) error {
+				mu.Lock()
+				runs[task.ID]++
+				mu.Unlock()
+				fmt.Printf("  t=%4dms %s ran %s (%s)\n",
+					time.Since(start).Milliseconds(), poller, task.ID, task.Payload)
+				return nil
+			})
+		}(poller)
+	}
+	wg.Wait()
+
+	fmt.Println()
+	fmt.Printf("  heartbeat ran %d times in ~2.2s (every 500ms, never twice per run)\n", runs["heartbeat"])
+	fmt.Printf("  report ran %d time\n", runs["report"])
+	sched.Cancel(context.Background(), "heartbeat")
+	fmt.Println()
+}
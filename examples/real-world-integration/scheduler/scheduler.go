@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Task is a scheduled job handed to the handler when it's due
+type Task struct {
+	ID      string
+	Payload string
+}
+
+// TaskHandler runs a due task
+type TaskHandler func(ctx context.Context, task Task) error
+
+// Scheduler runs one-shot and recurring tasks from a sorted set scored by
+// next-run time. Any number of pollers can share it: claiming due tasks is a
+// single Lua script, so each run goes to exactly one poller. Keys:
+//
+//	scheduler:<name>:due        zset   task ID → next run (unix ms)
+//	scheduler:<name>:tasks      hash   task ID → payload
+//	scheduler:<name>:intervals  hash   task ID → interval ms (recurring only)
+//
+// Delivery is at-most-once: a poller that crashes mid-task loses that run.
+// Use a stream consumer group when every run must complete.
+type Scheduler struct {
+	client    *redis.Client
+	due       string
+	tasks     string
+	intervals string
+	batch     int
+}
+
+func NewScheduler(client *redis.Client, name string) *Scheduler {
+	prefix := "scheduler:" + name
+	return &Scheduler{
+		client:    client,
+		due:       prefix + ":due",
+		tasks:     prefix + ":tasks",
+		intervals: prefix + ":intervals",
+		batch:     100,
+	}
+}
+
+// ScheduleOnce runs a task once at the given time
+func (s *Scheduler) ScheduleOnce(ctx context.Context, id, payload string, at time.Time) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.tasks, id, payload)
+		pipe.HDel(ctx, s.intervals, id)
+		pipe.ZAdd(ctx, s.due, redis.Z{Score: float64(at.UnixMilli()), Member: id})
+		return nil
+	})
+	return err
+}
+
+// ScheduleEvery runs a task every interval, starting one interval from now.
+// Intervals are stored in whole milliseconds, so anything shorter than 1ms is
+// rejected: it would round to 0 and the task would be due again forever.
+func (s *Scheduler) ScheduleEvery(ctx context.Context, id, payload string, interval time.Duration) error {
+	if interval < time.Millisecond {
+		return fmt.Errorf("scheduler: task %s: interval %v is below 1ms", id, interval)
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.tasks, id, payload)
+		pipe.HSet(ctx, s.intervals, id, interval.Milliseconds())
+		pipe.ZAdd(ctx, s.due, redis.Z{Score: float64(time.Now().Add(interval).UnixMilli()), Member: id})
+		return nil
+	})
+	return err
+}
+
+// Cancel removes a task so it never runs again
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.due, id)
+		pipe.HDel(ctx, s.tasks, id)
+		pipe.HDel(ctx, s.intervals, id)
+		return nil
+	})
+	return err
+}
+
+// claimDue atomically takes every task due by now. One-shot tasks are
+// removed; recurring tasks are pushed to their next run instead, so another
+// poller can't claim the same run.
+func (s *Scheduler) claimDue(ctx context.Context, now time.Time) ([]Task, error) {
+	script := `
+		local due, tasks, intervals = KEYS[1], KEYS[2], KEYS[3]
+		local now = tonumber(ARGV[1])
+		local limit = tonumber(ARGV[2])
+
+		local claimed = {}
+		local ids = redis.call('ZRANGEBYSCORE', due, '-inf', now, 'WITHSCORES', 'LIMIT', 0, limit)
+		for i = 1, #ids, 2 do
+			local id, runAt = ids[i], tonumber(ids[i + 1])
+			local payload = redis.call('HGET', tasks, id) or ''
+			local interval = redis.call('HGET', intervals, id)
+			if interval then
+				-- Keep the schedule aligned, but skip runs missed while no poller was up
+				local nextRun = runAt + tonumber(interval)
+				if nextRun <= now then
+					nextRun = now + tonumber(interval)
+				end
+				redis.call('ZADD', due, nextRun, id)
+			else
+				redis.call('ZREM', due, id)
+				redis.call('HDEL', tasks, id)
+			end
+			table.insert(claimed, id)
+			table.insert(claimed, payload)
+		end
+		return claimed
+	`
+
+	res, err := s.client.Eval(ctx, script, []string{s.due, s.tasks, s.intervals},
+		strconv.FormatInt(now.UnixMilli(), 10), s.batch).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		tasks = append(tasks, Task{ID: res[i], Payload: res[i+1]})
+	}
+	return tasks, nil
+}
+
+// Run polls for due tasks every pollInterval and runs them with handler
+// until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context, pollInterval time.Duration, handler TaskHandler) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		tasks, err := s.claimDue(ctx, time.Now())
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("scheduler: claim failed: %v", err)
+			continue
+		}
+		for _, task := range tasks {
+			if err := handler(ctx, task); err != nil {
+				log.Printf("scheduler: task %s failed: %v", task.ID, err)
+			}
+		}
+	}
+}
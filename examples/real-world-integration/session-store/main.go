@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UserSession is what the app keeps per logged-in user
+type UserSession struct {
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Cart     []string `json:"cart"`
+}
+
+func main() {
+	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
+	fmt.Println("║          Redis Session Store Example                         ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "localhost:6379",
+		Password: "",
+		DB:       0,
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	fmt.Println("✓ Connected to Redis")
+	fmt.Println()
+
+	sessions := NewSessionStore[UserSession](client, true)
+
+	// Login: create a session with a 30-minute idle timeout
+	id, err := sessions.Create(ctx, UserSession{UserID: "123", Username: "alice"}, 30*time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("✓ Created session %s…\n", id[:12])
+
+	// Simulate time passing, then an authenticated request
+	client.PExpire(ctx, sessionKey(id), 5*time.Minute) // Pretend 25 minutes went by
+	ttl, _ := client.TTL(ctx, sessionKey(id)).Result()
+	fmt.Printf("  TTL before request: %v\n", ttl)
+
+	session, found, err := sessions.Get(ctx, id)
+	if err != nil || !found {
+		log.Fatalf("session lookup failed: found=%v err=%v", found, err)
+	}
+	ttl, _ = client.TTL(ctx, sessionKey(id)).Result()
+	fmt.Printf("  Request by %s → TTL slid back to: %v\n", session.Username, ttl)
+
+	// Logout
+	sessions.Destroy(ctx, id)
+	_, found, _ = sessions.Get(ctx, id)
+	fmt.Printf("✓ Destroyed session, still found: %v\n", found)
+	fmt.Println()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionStore keeps sessions as strings under session:<id>. Each value is
+// "<ttl ms>|<json>", so Get and Refresh can slide the expiry back to the
+// session's own TTL without a separate lookup.
+type SessionStore[T any] struct {
+	client  *redis.Client
+	sliding bool // Reset the TTL on every Get
+}
+
+// NewSessionStore creates a store. With sliding set, every Get pushes the
+// session's expiry out by its full TTL, so active users stay logged in.
+func NewSessionStore[T any](client *redis.Client, sliding bool) *SessionStore[T] {
+	return &SessionStore[T]{
+		client:  client,
+		sliding: sliding,
+	}
+}
+
+func sessionKey(id string) string { return "session:" + id }
+
+// newSessionID returns 32 random bytes, URL-safe base64 encoded
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create stores data under a new random session ID that expires after ttl
+func (s *SessionStore[T]) Create(ctx context.Context, data T, ttl time.Duration) (string, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	value := strconv.FormatInt(ttl.Milliseconds(), 10) + "|" + string(payload)
+	// NX: never overwrite an existing session, however unlikely a collision is
+	ok, err := s.client.SetNX(ctx, sessionKey(id), value, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("session id collision")
+	}
+	return id, nil
+}
+
+// Get loads a session. A missing or expired session returns false.
+func (s *SessionStore[T]) Get(ctx context.Context, id string) (T, bool, error) {
+	return s.load(ctx, id, s.sliding)
+}
+
+// Refresh resets a session's TTL without reading it into the caller.
+// Returns false if the session no longer exists.
+func (s *SessionStore[T]) Refresh(ctx context.Context, id string) (bool, error) {
+	_, found, err := s.load(ctx, id, true)
+	return found, err
+}
+
+func (s *SessionStore[T]) load(ctx context.Context, id string, slide bool) (T, bool, error) {
+	var data T
+
+	// Read and (optionally) slide the TTL in one atomic step; the TTL to
+	// slide to is stored in front of the value
+	script := `
+		local value = redis.call("get", KEYS[1])
+		if not value then
+			return false
+		end
+		if ARGV[1] == "1" then
+			local ttl = tonumber(string.match(value, "^(%d+)|"))
+			if ttl and ttl > 0 then
+				redis.call("pexpire", KEYS[1], ttl)
+			end
+		end
+		return value
+	`
+	arg := "0"
+	if slide {
+		arg = "1"
+	}
+	value, err := s.client.Eval(ctx, script, []string{sessionKey(id)}, arg).Text()
+	if err == redis.Nil {
+		return data, false, nil
+	}
+	if err != nil {
+		return data, false, err
+	}
+
+	_, payload, ok := strings.Cut(value, "|")
+	if !ok {
+		return data, false, fmt.Errorf("session %s: malformed value", id)
+	}
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		return data, false, err
+	}
+	return data, true, nil
+}
+
+// Destroy deletes a session (logout)
+func (s *SessionStore[T]) Destroy(ctx context.Context, id string) error {
+	return s.client.Del(ctx, sessionKey(id)).Err()
+}
@@ -2,7 +2,10 @@ module learning-redis
 
 go 1.23
 
-require github.com/redis/go-redis/v9 v9.4.0
+require (
+	github.com/redis/go-redis/v9 v9.4.0
+	golang.org/x/sync v0.10.0
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdminHandler serves a read-only JSON view of the dataset, a lightweight
+// stand-in for Redis Commander:
+//
+//	GET /keys?match=user:*&cursor=0&count=10  one SCAN page
+//	GET /key/{name...}                        type, value and TTL of one key
+//	GET /types                                every key, grouped by type
+//	GET /info                                 INFO, as {section: {field: value}}
+//	GET /stats                                command and keyspace counters
+//
+// Handlers only take the read lock, one page or key at a time, so browsing
+// never stalls commands for long.
+func (r *MiniRedis) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /keys", r.handleKeys)
+	mux.HandleFunc("GET /key/{name...}", r.handleKey) // Key names may contain "/"
+	mux.HandleFunc("GET /types", r.handleTypes)
+	mux.HandleFunc("GET /info", r.handleInfo)
+	mux.HandleFunc("GET /stats", r.handleStats)
+	return mux
+}
+
+// ServeAdmin serves AdminHandler on addr (":0" picks a free port) in the
+// background. Listening happens before it returns, so a port that's in use
+// is reported here; the server's Addr is the address actually bound.
+func (r *MiniRedis) ServeAdmin(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{
+		Addr:              ln.Addr().String(),
+		Handler:           r.AdminHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go srv.Serve(ln)
+	return srv, nil
+}
+
+func (r *MiniRedis) handleKeys(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	var cursor uint64
+	if c := query.Get("cursor"); c != "" {
+		var err error
+		if cursor, err = strconv.ParseUint(c, 10, 64); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+	}
+	count, _ := strconv.Atoi(query.Get("count")) // scanPage defaults bad/missing counts
+
+	// scanPage, not Scan: browsing isn't a client command, so it isn't
+	// counted in the stats and keeps working under CLIENT PAUSE ALL
+	keys, next := r.scanPage(cursor, query.Get("match"), count)
+	if keys == nil {
+		keys = []string{} // [] rather than null
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"cursor": strconv.FormatUint(next, 10), // A string, like Redis's reply
+		"keys":   keys,
+	})
+}
+
+func (r *MiniRedis) handleKey(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+
+	r.mu.RLock()
+	val, exists := r.data[name]
+	expireTime, hasTTL := r.ttl[name]
+	if !exists || r.expired(name) {
+		r.mu.RUnlock()
+		writeJSONError(w, http.StatusNotFound, "no such key")
+		return
+	}
+	// Encode under the lock: hashes, lists and sets are mutated in place
+	body, err := json.Marshal(map[string]interface{}{
+		"key":   name,
+		"type":  typeName(val),
+		"value": plainValue(val),
+		"ttl":   ttlSeconds(expireTime, hasTTL),
+	})
+	r.mu.RUnlock()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// ttlSeconds is the TTL command's reply: seconds left, or -1 with no TTL
+func ttlSeconds(expireTime time.Time, hasTTL bool) int {
+	if !hasTTL {
+		return -1
+	}
+	return int(time.Until(expireTime).Seconds())
+}
+
+func (r *MiniRedis) handleTypes(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, r.keysByType())
+}
+
+func (r *MiniRedis) handleInfo(w http.ResponseWriter, req *http.Request) {
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+	for _, line := range strings.Split(r.Info("all"), "\r\n") {
+		if name, ok := strings.CutPrefix(line, "# "); ok {
+			current = make(map[string]string)
+			sections[strings.ToLower(name)] = current
+		} else if field, value, ok := strings.Cut(line, ":"); ok && current != nil {
+			current[field] = value
+		}
+	}
+	writeJSON(w, http.StatusOK, sections)
+}
+
+func (r *MiniRedis) handleStats(w http.ResponseWriter, req *http.Request) {
+	commands := make(map[string]int64)
+	var total int64
+	for cmd, stats := range r.LatencyHistogram() {
+		commands[strings.ToLower(cmd)] = stats.Calls
+		total += stats.Calls
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total_commands_processed": total,
+		"keyspace_hits":            r.hits.Load(),
+		"keyspace_misses":          r.misses.Load(),
+		"wrongtype_errors":         r.wrongTypeErrors.Load(),
+		"commands":                 commands,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
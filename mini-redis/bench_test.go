@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Run with: go test -bench . -benchmem
+//
+// Logging is off in every benchmark: fmt.Printf costs far more than the
+// commands themselves and would swamp the numbers.
+
+// newBenchRedis returns a quiet MiniRedis that is closed when b finishes
+func newBenchRedis(b *testing.B) *MiniRedis {
+	r := NewMiniRedis()
+	r.SetLogging(false)
+	b.Cleanup(r.Close)
+	b.ReportAllocs()
+	return r
+}
+
+func BenchmarkSet(b *testing.B) {
+	r := newBenchRedis(b)
+	for i := 0; i < b.N; i++ {
+		r.Set(fmt.Sprintf("key:%d", i), "value")
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	r := newBenchRedis(b)
+	for i := 0; i < 1000; i++ {
+		r.Set(fmt.Sprintf("key:%d", i), "value")
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Get(fmt.Sprintf("key:%d", i%1000))
+	}
+}
+
+func BenchmarkHSet(b *testing.B) {
+	r := newBenchRedis(b)
+	for i := 0; i < b.N; i++ {
+		r.HSet("hash", fmt.Sprintf("field:%d", i%1000), "value")
+	}
+}
+
+func BenchmarkSAdd(b *testing.B) {
+	r := newBenchRedis(b)
+	for i := 0; i < b.N; i++ {
+		r.SAdd("set", fmt.Sprintf("member:%d", i%1000))
+	}
+}
+
+func BenchmarkLPushRPop(b *testing.B) {
+	r := newBenchRedis(b)
+	for i := 0; i < b.N; i++ {
+		r.LPush("queue", "task")
+		r.RPop("queue")
+	}
+}
+
+// BenchmarkLPushBatch pushes n values in one LPUSH. Each value is prepended
+// by copying the list, so doubling n roughly quadruples the time: O(n²).
+// Real Redis lists are quicklists (linked listpacks): O(1) per value.
+func BenchmarkLPushBatch(b *testing.B) {
+	for _, n := range []int{2_000, 4_000, 8_000} {
+		b.Run(fmt.Sprintf("values=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			values := make([]string, n)
+			for i := range values {
+				values[i] = "v"
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.Del("biglist")
+				r.LPush("biglist", values...)
+			}
+		})
+	}
+}
+
+// collectionSizes are the element counts the collection benchmarks run at,
+// to show how each command scales with the size of the key it touches
+var collectionSizes = []int{10, 100, 1_000, 10_000}
+
+// names returns n distinct strings like "field:0", built up front so
+// fmt.Sprintf doesn't show up in the timed loop
+func names(prefix string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("%s:%d", prefix, i)
+	}
+	return out
+}
+
+// BenchmarkHash overwrites one field of, and reads back, a hash of n fields.
+// HSET stays flat; HGETALL copies every field, so it grows linearly.
+func BenchmarkHash(b *testing.B) {
+	for _, n := range collectionSizes {
+		fields := names("field", n)
+
+		b.Run(fmt.Sprintf("HSet/fields=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			for _, f := range fields {
+				r.HSet("hash", f, "value")
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.HSet("hash", fields[i%n], "value")
+			}
+		})
+
+		b.Run(fmt.Sprintf("HGetAll/fields=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			for _, f := range fields {
+				r.HSet("hash", f, "value")
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.HGetAll("hash")
+			}
+		})
+	}
+}
+
+// BenchmarkSetCollection adds to, and lists, a set of n members.
+// SADD stays flat; SMEMBERS copies every member, so it grows linearly.
+func BenchmarkSetCollection(b *testing.B) {
+	for _, n := range collectionSizes {
+		members := names("member", n)
+
+		b.Run(fmt.Sprintf("SAdd/members=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			r.SAdd("set", members...)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.SAdd("set", members[i%n])
+			}
+		})
+
+		b.Run(fmt.Sprintf("SMembers/members=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			r.SAdd("set", members...)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.SMembers("set")
+			}
+		})
+	}
+}
+
+// BenchmarkSortedSet updates a score in, and ranges over, a sorted set of n
+// members. ZRANGE sorts the whole set per call (there's no skiplist), so even
+// the top 10 costs O(n log n) - real Redis answers that in O(log n + 10).
+func BenchmarkSortedSet(b *testing.B) {
+	for _, n := range collectionSizes {
+		members := names("member", n)
+		fill := func(r *MiniRedis) {
+			for i, m := range members {
+				r.ZAdd("zset", float64(i), m)
+			}
+		}
+
+		b.Run(fmt.Sprintf("ZAdd/members=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			fill(r)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.ZAdd("zset", float64(i), members[i%n])
+			}
+		})
+
+		b.Run(fmt.Sprintf("ZRangeTop10/members=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			fill(r)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.ZRange("zset", 0, 9)
+			}
+		})
+
+		b.Run(fmt.Sprintf("ZRangeAll/members=%d", n), func(b *testing.B) {
+			r := newBenchRedis(b)
+			fill(r)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.ZRange("zset", 0, -1)
+			}
+		})
+	}
+}
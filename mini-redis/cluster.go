@@ -0,0 +1,56 @@
+package main
+
+import "strings"
+
+// clusterSlots is the number of hash slots in Redis Cluster
+const clusterSlots = 16384
+
+// crc16 is CRC-16/XMODEM (poly 0x1021, init 0), the variant Redis Cluster uses
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// KeySlot returns the cluster hash slot for key: CRC16(key) mod 16384.
+// If the key contains a non-empty hash tag like "{user:1}:cart", only the
+// part inside the first {...} is hashed, so "{user:1}:cart" and
+// "{user:1}:orders" land in the same slot (and can be used together in
+// MULTI or a Lua script).
+func KeySlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return crc16(key) % clusterSlots
+}
+
+// SlotRange is one entry of a CLUSTER SLOTS reply
+type SlotRange struct {
+	Start, End uint16
+	Node       string
+}
+
+// ClusterKeySlot is CLUSTER KEYSLOT
+func (r *MiniRedis) ClusterKeySlot(key string) uint16 {
+	slot := KeySlot(key)
+	r.logf("CLUSTER KEYSLOT %s = %d\n", key, slot)
+	return slot
+}
+
+// ClusterSlots is CLUSTER SLOTS. MiniRedis is a single node, so it owns
+// every slot; a real cluster splits them across masters (e.g. 0-5460,
+// 5461-10922, 10923-16383 with three).
+func (r *MiniRedis) ClusterSlots() []SlotRange {
+	return []SlotRange{{Start: 0, End: clusterSlots - 1, Node: "127.0.0.1:6379"}}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// encodingConfig holds the thresholds below which Redis keeps a value in a
+// compact encoding (one contiguous listpack or intset) and past which it
+// converts it to a real hash table / skiplist / quicklist. Encodings are
+// worked out when a key is inspected, so after CONFIG SET existing keys are
+// reclassified on their next access.
+type encodingConfig struct {
+	hashMaxListpackEntries int
+	hashMaxListpackValue   int // bytes
+	setMaxIntsetEntries    int
+	setMaxListpackEntries  int
+	setMaxListpackValue    int // bytes
+	zsetMaxListpackEntries int
+	zsetMaxListpackValue   int // bytes
+	// listMaxListpackSize is entries per quicklist node. Redis's default
+	// (-2) caps nodes at 8KB instead; only entry counts are simulated here.
+	listMaxListpackSize int
+}
+
+// defaultEncodingConfig returns Redis's defaults
+func defaultEncodingConfig() encodingConfig {
+	return encodingConfig{
+		hashMaxListpackEntries: 128,
+		hashMaxListpackValue:   64,
+		setMaxIntsetEntries:    512,
+		setMaxListpackEntries:  128,
+		setMaxListpackValue:    64,
+		zsetMaxListpackEntries: 128,
+		zsetMaxListpackValue:   64,
+		listMaxListpackSize:    128,
+	}
+}
+
+// params maps each CONFIG parameter name to its setting
+func (c *encodingConfig) params() map[string]*int {
+	return map[string]*int{
+		"hash-max-listpack-entries": &c.hashMaxListpackEntries,
+		"hash-max-listpack-value":   &c.hashMaxListpackValue,
+		"set-max-intset-entries":    &c.setMaxIntsetEntries,
+		"set-max-listpack-entries":  &c.setMaxListpackEntries,
+		"set-max-listpack-value":    &c.setMaxListpackValue,
+		"zset-max-listpack-entries": &c.zsetMaxListpackEntries,
+		"zset-max-listpack-value":   &c.zsetMaxListpackValue,
+		"list-max-listpack-size":    &c.listMaxListpackSize,
+	}
+}
+
+// ConfigSet is CONFIG SET for the encoding thresholds above
+func (r *MiniRedis) ConfigSet(param, value string) error {
+	defer r.observe("CONFIG", time.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	setting, ok := r.config.params()[param]
+	if !ok {
+		return fmt.Errorf("ERR Unknown option or number of arguments for CONFIG SET - '%s'", param)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || (n == 0 && param == "list-max-listpack-size") {
+		return fmt.Errorf("ERR Invalid argument '%s' for CONFIG SET '%s'", value, param)
+	}
+
+	*setting = n
+	r.logf("CONFIG SET %s %d\n", param, n)
+	return nil
+}
+
+// ConfigGet is CONFIG GET: every parameter whose name matches the glob
+// pattern, with its current value
+func (r *MiniRedis) ConfigGet(pattern string) map[string]string {
+	defer r.observe("CONFIG", time.Now())
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]string)
+	for param, setting := range r.config.params() {
+		if matchGlob(pattern, param) {
+			result[param] = strconv.Itoa(*setting)
+		}
+	}
+	return result
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,13 +24,46 @@ type MiniRedis struct {
 
 	// Lock for thread-safe operations (Redis is single-threaded, but Go needs this)
 	mu sync.RWMutex
+
+	// quiet turns off the per-command log lines (e.g. when timing operations)
+	quiet atomic.Bool
+
+	// Keyspace hits and misses of read commands, and commands run against
+	// a key of the wrong type, for INFO stats
+	hits, misses    atomic.Int64
+	wrongTypeErrors atomic.Int64
+
+	// Encoding thresholds, changed with ConfigSet (see config.go)
+	config encodingConfig
+
+	// Per-command latency histograms (see latency.go); separate lock so
+	// read commands holding mu.RLock can record too
+	latency   map[string]*LatencyStats
+	latencyMu sync.Mutex
+
+	// CLIENT PAUSE state (see pause.go)
+	pause pauseState
+
+	// Every key sorted in SCAN order (see sortedKeys), rebuilt lazily once
+	// keys have been added or removed. scanMu serializes rebuilds by readers
+	// holding mu.RLock; writers only set scanStale.
+	scanIndex []hashedKey
+	scanStale atomic.Bool
+	scanMu    sync.Mutex
+
+	// done stops the background expiration goroutine
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 // NewMiniRedis creates a new MiniRedis instance
 func NewMiniRedis() *MiniRedis {
 	redis := &MiniRedis{
-		data: make(map[string]interface{}),
-		ttl:  make(map[string]time.Time),
+		data:    make(map[string]interface{}),
+		ttl:     make(map[string]time.Time),
+		latency: make(map[string]*LatencyStats),
+		config:  defaultEncodingConfig(),
+		done:    make(chan struct{}),
 	}
 
 	// Start background TTL cleanup (like Redis does)
@@ -31,168 +72,402 @@ func NewMiniRedis() *MiniRedis {
 	return redis
 }
 
+// SetLogging turns the per-command log lines on or off.
+// Printing costs far more than the operations themselves, so turn it off
+// before measuring anything.
+func (r *MiniRedis) SetLogging(enabled bool) {
+	r.quiet.Store(!enabled)
+}
+
+// logf prints a command log line unless logging is off
+func (r *MiniRedis) logf(format string, args ...interface{}) {
+	if !r.quiet.Load() {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Close shuts the instance down: the background expiration goroutine
+// stops. Like SHUTDOWN NOSAVE, nothing is persisted - call ExportJSON first
+// to keep the data. Safe to call more than once.
+func (r *MiniRedis) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.logf("SHUTDOWN\n")
+	})
+}
+
 // expireKeys runs in background and removes expired keys
 func (r *MiniRedis) expireKeys() {
 	ticker := time.NewTicker(100 * time.Millisecond)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+		if r.paused() {
+			continue // Like Redis, keys don't expire while clients are paused
+		}
+
 		r.mu.Lock()
 		now := time.Now()
 		for key, expireTime := range r.ttl {
 			if now.After(expireTime) {
-				delete(r.data, key)
-				delete(r.ttl, key)
-				fmt.Printf("[TTL] Key '%s' expired and deleted\n", key)
+				r.deleteKey(key)
+				r.logf("[TTL] Key '%s' expired and deleted\n", key)
 			}
 		}
 		r.mu.Unlock()
 	}
 }
 
-// isExpired checks if a key has expired
+// isExpired checks if a key has expired and deletes it if so.
+// It modifies the dataset, so callers must hold the write lock;
+// read paths use expired instead.
 func (r *MiniRedis) isExpired(key string) bool {
 	if expireTime, exists := r.ttl[key]; exists {
 		if time.Now().After(expireTime) {
-			delete(r.data, key)
-			delete(r.ttl, key)
+			r.deleteKey(key)
 			return true
 		}
 	}
 	return false
 }
 
+// expired reports whether a key's TTL has passed without deleting it, so
+// it is safe under the read lock. The background sweep removes the key.
+func (r *MiniRedis) expired(key string) bool {
+	expireTime, exists := r.ttl[key]
+	return exists && time.Now().After(expireTime)
+}
+
+// setKey stores val under key, marking the scan index stale if the key is
+// new. Callers must hold the write lock.
+func (r *MiniRedis) setKey(key string, val interface{}) {
+	if _, exists := r.data[key]; !exists {
+		r.scanStale.Store(true)
+	}
+	r.data[key] = val
+}
+
+// deleteKey removes a key and its TTL, marking the scan index stale.
+// Callers must hold the write lock.
+func (r *MiniRedis) deleteKey(key string) {
+	if _, exists := r.data[key]; exists {
+		r.scanStale.Store(true)
+	}
+	delete(r.data, key)
+	delete(r.ttl, key)
+}
+
+// ErrWrongType is returned by the methods that return errors when a key
+// holds another type
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// ErrNil is returned when a key or field doesn't exist (like redis.Nil)
+var ErrNil = errors.New("mini-redis: nil")
+
+// ErrNotInteger is returned by INCR when the value isn't an integer
+var ErrNotInteger = errors.New("ERR value is not an integer or out of range")
+
+// lookupRead returns a key's value for a read command, counting a keyspace
+// hit or miss like Redis does. Expired keys are misses. Callers must hold
+// at least the read lock.
+func (r *MiniRedis) lookupRead(key string) (interface{}, bool) {
+	val, exists := r.data[key]
+	if !exists || r.expired(key) {
+		r.misses.Add(1)
+		return nil, false
+	}
+	r.hits.Add(1)
+	return val, true
+}
+
+// wrongType logs the error for a command run against a key of another
+// type (real Redis replies WRONGTYPE), counts it for INFO stats and returns
+// ErrWrongType. A climbing count usually means two parts of an app disagree
+// about what a key holds.
+func (r *MiniRedis) wrongType(key, want string) error {
+	r.wrongTypeErrors.Add(1)
+	r.logf("ERROR: Key '%s' is not a %s\n", key, want)
+	return ErrWrongType
+}
+
+// Read and pop methods return (value, ok). ok is false - the equivalent of
+// Redis's nil reply - when the key doesn't exist, has expired, holds another
+// type (logged as an ERROR, like WRONGTYPE), or there is nothing to return
+// (missing hash field, empty list). Read methods never modify the dataset.
+//
+// Some have an unexported twin (get, incr, hset, ...) that returns ErrNil or
+// ErrWrongType instead, decided under the same lock as the command itself;
+// the Store adapter uses those to tell the cases apart.
+
 // ===== STRING OPERATIONS =====
 
 // Set stores a string value
 func (r *MiniRedis) Set(key, value string) {
+	defer r.observe("SET", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.data[key] = value
+	r.setKey(key, value)
 	delete(r.ttl, key) // Clear any TTL
-	fmt.Printf("SET %s = %s\n", key, value)
+	r.logf("SET %s = %s\n", key, value)
 }
 
 // Get retrieves a string value
 func (r *MiniRedis) Get(key string) (string, bool) {
+	val, err := r.get(key)
+	return val, err == nil
+}
+
+func (r *MiniRedis) get(key string) (string, error) {
+	defer r.observe("GET", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.isExpired(key) {
-		return "", false
-	}
-
-	val, exists := r.data[key]
+	val, exists := r.lookupRead(key)
 	if !exists {
-		return "", false
+		return "", ErrNil
 	}
 
 	// Type assertion - in real Redis, this would be handled by the protocol
 	strVal, ok := val.(string)
 	if !ok {
-		fmt.Printf("ERROR: Key '%s' is not a string\n", key)
-		return "", false
+		return "", r.wrongType(key, "string")
+	}
+
+	r.logf("GET %s = %s\n", key, strVal)
+	return strVal, nil
+}
+
+// Incr increments an integer string by 1, creating it at 0 if missing.
+// Like Redis, it keeps any existing TTL.
+func (r *MiniRedis) Incr(key string) (int64, bool) {
+	n, err := r.incr(key)
+	return n, err == nil
+}
+
+func (r *MiniRedis) incr(key string) (int64, error) {
+	defer r.observe("INCR", time.Now())
+	r.waitUnpaused(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.isExpired(key)
+
+	var n int64
+	if val, exists := r.data[key]; exists {
+		strVal, ok := val.(string)
+		if !ok {
+			return 0, r.wrongType(key, "string")
+		}
+		parsed, err := strconv.ParseInt(strVal, 10, 64)
+		if err != nil {
+			r.logf("ERROR: Key '%s' is not an integer\n", key)
+			return 0, ErrNotInteger
+		}
+		n = parsed
 	}
 
-	fmt.Printf("GET %s = %s\n", key, strVal)
-	return strVal, true
+	n++
+	r.setKey(key, strconv.FormatInt(n, 10))
+	r.logf("INCR %s = %d\n", key, n)
+	return n, nil
 }
 
 // ===== HASH OPERATIONS =====
 
 // HSet sets a field in a hash
 func (r *MiniRedis) HSet(key, field, value string) {
+	r.hset(key, field, value)
+}
+
+func (r *MiniRedis) hset(key, field, value string) error {
+	defer r.observe("HSET", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.isExpired(key)
+
 	// Get or create hash
 	var hash map[string]string
 	if val, exists := r.data[key]; exists {
-		hash, _ = val.(map[string]string)
+		var ok bool
+		if hash, ok = val.(map[string]string); !ok {
+			return r.wrongType(key, "hash")
+		}
 	} else {
 		hash = make(map[string]string)
-		r.data[key] = hash
+		r.setKey(key, hash)
 	}
 
 	hash[field] = value
-	fmt.Printf("HSET %s %s = %s\n", key, field, value)
+	r.logf("HSET %s %s = %s\n", key, field, value)
+	return nil
 }
 
 // HGet gets a field from a hash
 func (r *MiniRedis) HGet(key, field string) (string, bool) {
+	val, err := r.hget(key, field)
+	return val, err == nil
+}
+
+func (r *MiniRedis) hget(key, field string) (string, error) {
+	defer r.observe("HGET", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.isExpired(key) {
-		return "", false
-	}
-
-	val, exists := r.data[key]
+	val, exists := r.lookupRead(key)
 	if !exists {
-		return "", false
+		return "", ErrNil
 	}
 
 	hash, ok := val.(map[string]string)
 	if !ok {
-		fmt.Printf("ERROR: Key '%s' is not a hash\n", key)
-		return "", false
+		return "", r.wrongType(key, "hash")
 	}
 
 	value, exists := hash[field]
-	if exists {
-		fmt.Printf("HGET %s %s = %s\n", key, field, value)
+	if !exists {
+		return "", ErrNil
 	}
-	return value, exists
+	r.logf("HGET %s %s = %s\n", key, field, value)
+	return value, nil
 }
 
 // HGetAll gets all fields from a hash
 func (r *MiniRedis) HGetAll(key string) (map[string]string, bool) {
+	defer r.observe("HGETALL", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.isExpired(key) {
+	val, exists := r.lookupRead(key)
+	if !exists {
 		return nil, false
 	}
 
-	val, exists := r.data[key]
-	if !exists {
+	hash, ok := val.(map[string]string)
+	if !ok {
+		r.wrongType(key, "hash")
 		return nil, false
 	}
 
+	r.logf("HGETALL %s = %v\n", key, hash)
+	return maps.Clone(hash), true // A copy, like SMEMBERS: the caller reads it after we unlock
+}
+
+// HMGet returns the values of several fields in one call, in the order
+// asked, with nil for missing fields (or every field, if the key is missing)
+func (r *MiniRedis) HMGet(key string, fields ...string) ([]*string, error) {
+	defer r.observe("HMGET", time.Now())
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	values := make([]*string, len(fields))
+	val, exists := r.lookupRead(key)
+	if !exists {
+		return values, nil
+	}
 	hash, ok := val.(map[string]string)
 	if !ok {
-		fmt.Printf("ERROR: Key '%s' is not a hash\n", key)
-		return nil, false
+		return nil, r.wrongType(key, "hash")
+	}
+
+	for i, field := range fields {
+		if value, ok := hash[field]; ok {
+			values[i] = &value
+		}
 	}
 
-	fmt.Printf("HGETALL %s = %v\n", key, hash)
-	return hash, true
+	r.logf("HMGET %s %v\n", key, fields)
+	return values, nil
 }
 
 // ===== LIST OPERATIONS =====
 
 // LPush pushes values to the left (head) of a list
 func (r *MiniRedis) LPush(key string, values ...string) {
+	r.lpush(key, values...)
+}
+
+func (r *MiniRedis) lpush(key string, values ...string) error {
+	defer r.observe("LPUSH", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.isExpired(key)
+
 	// Get or create list
 	var list []string
 	if val, exists := r.data[key]; exists {
-		list, _ = val.([]string)
+		var ok bool
+		if list, ok = val.([]string); !ok {
+			return r.wrongType(key, "list")
+		}
 	} else {
 		list = []string{}
 	}
 
-	// Prepend values (reverse order to match Redis behavior)
-	for i := len(values) - 1; i >= 0; i-- {
-		list = append([]string{values[i]}, list...)
+	// Prepend values one at a time, like Redis: LPUSH a b c leaves [c b a]
+	for _, value := range values {
+		list = append([]string{value}, list...)
 	}
 
-	r.data[key] = list
-	fmt.Printf("LPUSH %s %v (length: %d)\n", key, values, len(list))
+	r.setKey(key, list)
+	r.logf("LPUSH %s %v (length: %d)\n", key, values, len(list))
+	return nil
 }
 
-// RPop pops and returns a value from the right (tail) of a list
-func (r *MiniRedis) RPop(key string) (string, bool) {
+// RPush pushes values to the right (tail) of a list
+func (r *MiniRedis) RPush(key string, values ...string) {
+	defer r.observe("RPUSH", time.Now())
+	r.waitUnpaused(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.isExpired(key)
+
+	// Get or create list
+	var list []string
+	if val, exists := r.data[key]; exists {
+		var ok bool
+		if list, ok = val.([]string); !ok {
+			r.wrongType(key, "list")
+			return
+		}
+	} else {
+		list = []string{}
+	}
+
+	// Appending is amortized O(1) per value, unlike LPush's prepend
+	list = append(list, values...)
+
+	r.setKey(key, list)
+	r.logf("RPUSH %s %v (length: %d)\n", key, values, len(list))
+}
+
+// LPop pops and returns a value from the left (head) of a list
+func (r *MiniRedis) LPop(key string) (string, bool) {
+	defer r.observe("LPOP", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -206,32 +481,131 @@ func (r *MiniRedis) RPop(key string) (string, bool) {
 	}
 
 	list, ok := val.([]string)
-	if !ok || len(list) == 0 {
+	if !ok {
+		r.wrongType(key, "list")
 		return "", false
 	}
+	if len(list) == 0 {
+		return "", false
+	}
+
+	// Pop from left
+	value := list[0]
+	r.data[key] = list[1:]
+	if len(list) == 1 {
+		// Like Redis, a list that becomes empty is deleted
+		r.deleteKey(key)
+	}
+
+	r.logf("LPOP %s = %s\n", key, value)
+	return value, true
+}
+
+// RPop pops and returns a value from the right (tail) of a list
+func (r *MiniRedis) RPop(key string) (string, bool) {
+	val, err := r.rpop(key)
+	return val, err == nil
+}
+
+func (r *MiniRedis) rpop(key string) (string, error) {
+	defer r.observe("RPOP", time.Now())
+	r.waitUnpaused(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isExpired(key) {
+		return "", ErrNil
+	}
+
+	val, exists := r.data[key]
+	if !exists {
+		return "", ErrNil
+	}
+
+	list, ok := val.([]string)
+	if !ok {
+		return "", r.wrongType(key, "list")
+	}
+	if len(list) == 0 {
+		return "", ErrNil
+	}
 
 	// Pop from right
 	value := list[len(list)-1]
 	r.data[key] = list[:len(list)-1]
+	if len(list) == 1 {
+		// Like Redis, a list that becomes empty is deleted
+		r.deleteKey(key)
+	}
 
-	fmt.Printf("RPOP %s = %s\n", key, value)
-	return value, true
+	r.logf("RPOP %s = %s\n", key, value)
+	return value, nil
+}
+
+// LPosMany returns the index of the first occurrence of each element (like
+// one LPOS per element), in the order asked, with -1 for elements not in
+// the list. It scans the list once, however many elements are asked for.
+func (r *MiniRedis) LPosMany(key string, elements ...string) ([]int64, error) {
+	defer r.observe("LPOS", time.Now())
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	positions := make([]int64, len(elements))
+	for i := range positions {
+		positions[i] = -1
+	}
+	val, exists := r.lookupRead(key)
+	if !exists {
+		return positions, nil
+	}
+	list, ok := val.([]string)
+	if !ok {
+		return nil, r.wrongType(key, "list")
+	}
+
+	wanted := make(map[string]int64, len(elements))
+	for _, element := range elements {
+		wanted[element] = -1
+	}
+	for i, value := range list {
+		if pos, ok := wanted[value]; ok && pos == -1 {
+			wanted[value] = int64(i)
+		}
+	}
+	for i, element := range elements {
+		positions[i] = wanted[element]
+	}
+
+	r.logf("LPOS %s %v = %v\n", key, elements, positions)
+	return positions, nil
 }
 
 // ===== SET OPERATIONS =====
 
 // SAdd adds members to a set
 func (r *MiniRedis) SAdd(key string, members ...string) int {
+	defer r.observe("SADD", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.isExpired(key)
+
 	// Get or create set (using map for uniqueness)
 	var set map[string]bool
 	if val, exists := r.data[key]; exists {
-		set, _ = val.(map[string]bool)
+		var ok bool
+		if set, ok = val.(map[string]bool); !ok {
+			r.wrongType(key, "set")
+			return 0
+		}
 	} else {
 		set = make(map[string]bool)
-		r.data[key] = set
+		r.setKey(key, set)
 	}
 
 	added := 0
@@ -242,26 +616,26 @@ func (r *MiniRedis) SAdd(key string, members ...string) int {
 		}
 	}
 
-	fmt.Printf("SADD %s %v (added: %d, total: %d)\n", key, members, added, len(set))
+	r.logf("SADD %s %v (added: %d, total: %d)\n", key, members, added, len(set))
 	return added
 }
 
 // SMembers returns all members of a set
 func (r *MiniRedis) SMembers(key string) ([]string, bool) {
+	defer r.observe("SMEMBERS", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.isExpired(key) {
-		return nil, false
-	}
-
-	val, exists := r.data[key]
+	val, exists := r.lookupRead(key)
 	if !exists {
 		return nil, false
 	}
 
 	set, ok := val.(map[string]bool)
 	if !ok {
+		r.wrongType(key, "set")
 		return nil, false
 	}
 
@@ -270,28 +644,254 @@ func (r *MiniRedis) SMembers(key string) ([]string, bool) {
 		members = append(members, member)
 	}
 
-	fmt.Printf("SMEMBERS %s = %v\n", key, members)
+	r.logf("SMEMBERS %s = %v\n", key, members)
 	return members, true
 }
 
+// SMIsMember reports, for each member in the order asked, whether it is in
+// the set - one round trip instead of one SISMEMBER per member
+func (r *MiniRedis) SMIsMember(key string, members ...string) ([]bool, error) {
+	defer r.observe("SMISMEMBER", time.Now())
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found := make([]bool, len(members))
+	val, exists := r.lookupRead(key)
+	if !exists {
+		return found, nil
+	}
+	set, ok := val.(map[string]bool)
+	if !ok {
+		return nil, r.wrongType(key, "set")
+	}
+
+	for i, member := range members {
+		found[i] = set[member]
+	}
+
+	r.logf("SMISMEMBER %s %v = %v\n", key, members, found)
+	return found, nil
+}
+
+// SInterCard returns the size of the intersection of the sets at keys
+// without building it, stopping once limit members match (0 = no limit).
+// Missing keys count as empty sets, so any missing key makes the answer 0.
+func (r *MiniRedis) SInterCard(keys []string, limit int) (int64, error) {
+	defer r.observe("SINTERCARD", time.Now())
+	r.waitUnpaused(false)
+
+	if limit < 0 {
+		return 0, errors.New("ERR LIMIT can't be negative")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Check every key's type first, like Redis, so a WRONGTYPE key is
+	// reported even when another key is missing
+	sets := make([]map[string]bool, 0, len(keys))
+	missing := false
+	for _, key := range keys {
+		val, exists := r.lookupRead(key)
+		if !exists {
+			missing = true
+			continue
+		}
+		set, ok := val.(map[string]bool)
+		if !ok {
+			return 0, r.wrongType(key, "set")
+		}
+		sets = append(sets, set)
+	}
+	if missing || len(sets) == 0 {
+		r.logf("SINTERCARD %v = 0\n", keys)
+		return 0, nil
+	}
+
+	// Walk the smallest set and probe the others: O(N*M) where N is the
+	// smallest set's size, and a LIMIT can stop it much earlier
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	var count int64
+	for member := range sets[0] {
+		inAll := true
+		for _, other := range sets[1:] {
+			if !other[member] {
+				inAll = false
+				break
+			}
+		}
+		if !inAll {
+			continue
+		}
+		count++
+		if limit > 0 && count == int64(limit) {
+			break
+		}
+	}
+
+	r.logf("SINTERCARD %v LIMIT %d = %d\n", keys, limit, count)
+	return count, nil
+}
+
+// ===== SORTED SET OPERATIONS =====
+
+// Sorted sets are stored as map[string]float64 (member -> score). Real Redis
+// pairs that map with a skiplist ordered by score, which is what makes
+// ZRANGE fast; here ZRANGE has to sort the whole set on every call.
+
+// ZAdd sets a member's score, returning 1 if the member is new
+func (r *MiniRedis) ZAdd(key string, score float64, member string) int {
+	defer r.observe("ZADD", time.Now())
+	r.waitUnpaused(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.isExpired(key)
+
+	var zset map[string]float64
+	if val, exists := r.data[key]; exists {
+		var ok bool
+		if zset, ok = val.(map[string]float64); !ok {
+			r.wrongType(key, "sorted set")
+			return 0
+		}
+	} else {
+		zset = make(map[string]float64)
+		r.setKey(key, zset)
+	}
+
+	_, existed := zset[member]
+	zset[member] = score
+	r.logf("ZADD %s %g %s\n", key, score, member)
+	if existed {
+		return 0
+	}
+	return 1
+}
+
+// ZMScore returns the scores of several members in the order asked, with
+// nil for members that aren't in the sorted set
+func (r *MiniRedis) ZMScore(key string, members ...string) ([]*float64, error) {
+	defer r.observe("ZMSCORE", time.Now())
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := make([]*float64, len(members))
+	val, exists := r.lookupRead(key)
+	if !exists {
+		return scores, nil
+	}
+	zset, ok := val.(map[string]float64)
+	if !ok {
+		return nil, r.wrongType(key, "sorted set")
+	}
+
+	for i, member := range members {
+		if score, ok := zset[member]; ok {
+			scores[i] = &score
+		}
+	}
+
+	r.logf("ZMSCORE %s %v\n", key, members)
+	return scores, nil
+}
+
+// ZRange returns the members ranked start..stop (inclusive) by score, lowest
+// first, ties broken by member. Negative indexes count from the end, so
+// ZRange(key, 0, -1) returns the whole set.
+func (r *MiniRedis) ZRange(key string, start, stop int) ([]string, error) {
+	defer r.observe("ZRANGE", time.Now())
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	val, exists := r.lookupRead(key)
+	if !exists {
+		return []string{}, nil
+	}
+	zset, ok := val.(map[string]float64)
+	if !ok {
+		return nil, r.wrongType(key, "sorted set")
+	}
+
+	// O(n log n) per call - the skiplist would make this O(log n + m)
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+
+	n := len(members)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop += n
+	}
+	stop = min(stop, n-1)
+	if start > stop {
+		return []string{}, nil
+	}
+
+	r.logf("ZRANGE %s %d %d\n", key, start, stop)
+	return members[start : stop+1], nil
+}
+
 // ===== TTL OPERATIONS =====
 
 // Expire sets a TTL on a key
 func (r *MiniRedis) Expire(key string, seconds int) bool {
+	defer r.observe("EXPIRE", time.Now())
+	if !r.expireIn(key, time.Duration(seconds)*time.Second) {
+		return false
+	}
+	r.logf("EXPIRE %s %d seconds\n", key, seconds)
+	return true
+}
+
+// PExpire sets a TTL on a key in milliseconds
+func (r *MiniRedis) PExpire(key string, ms int64) bool {
+	defer r.observe("PEXPIRE", time.Now())
+	if !r.expireIn(key, time.Duration(ms)*time.Millisecond) {
+		return false
+	}
+	r.logf("PEXPIRE %s %d ms\n", key, ms)
+	return true
+}
+
+// expireIn sets key to expire after d, returning false if it doesn't exist
+func (r *MiniRedis) expireIn(key string, d time.Duration) bool {
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.isExpired(key)
+
 	if _, exists := r.data[key]; !exists {
 		return false
 	}
 
-	r.ttl[key] = time.Now().Add(time.Duration(seconds) * time.Second)
-	fmt.Printf("EXPIRE %s %d seconds\n", key, seconds)
+	r.ttl[key] = time.Now().Add(d)
 	return true
 }
 
 // TTL returns the remaining time to live in seconds
 func (r *MiniRedis) TTL(key string) int {
+	defer r.observe("TTL", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -308,7 +908,7 @@ func (r *MiniRedis) TTL(key string) int {
 		return -2
 	}
 
-	fmt.Printf("TTL %s = %d seconds\n", key, int(remaining))
+	r.logf("TTL %s = %d seconds\n", key, int(remaining))
 	return int(remaining)
 }
 
@@ -316,48 +916,261 @@ func (r *MiniRedis) TTL(key string) int {
 
 // Keys returns all keys (simplified - real Redis uses SCAN)
 func (r *MiniRedis) Keys() []string {
+	defer r.observe("KEYS", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	keys := make([]string, 0, len(r.data))
 	for key := range r.data {
-		if !r.isExpired(key) {
+		if !r.expired(key) {
 			keys = append(keys, key)
 		}
 	}
 
-	fmt.Printf("KEYS * = %v\n", keys)
+	r.logf("KEYS * = %v\n", keys)
 	return keys
 }
 
+// Scan returns up to count keys (default 10) matching the glob pattern
+// (empty = all) starting at cursor, and the cursor for the next call; a
+// returned cursor of 0 means the iteration is complete. Unlike Keys, each
+// call only looks at one page, so a huge keyspace doesn't mean one huge reply.
+//
+// Keys are visited in order of a hash of the key, and the cursor is the
+// hash where the next page starts. A key's hash never changes, so each page
+// covers its own slice of the hash space: a key present for the whole
+// iteration is returned exactly once, however many other keys are added or
+// deleted in between (keys added or deleted mid-iteration may or may not be
+// returned - the same guarantee Redis gives). A position in sorted order
+// wouldn't do: one insert before the cursor shifts every later key along,
+// so the next page repeats one.
+func (r *MiniRedis) Scan(cursor uint64, match string, count int) ([]string, uint64) {
+	defer r.observe("SCAN", time.Now())
+	r.waitUnpaused(false)
+
+	keys, next := r.scanPage(cursor, match, count)
+	r.logf("SCAN %d MATCH %s COUNT %d = %v (next: %d)\n", cursor, match, count, keys, next)
+	return keys, next
+}
+
+// scanPage is Scan without the logging, latency tracking and pause check,
+// for helpers and the admin API that walk the keyspace a page at a time.
+// Each page is a binary search into the sorted index plus count keys.
+func (r *MiniRedis) scanPage(cursor uint64, match string, count int) ([]string, uint64) {
+	if count <= 0 {
+		count = 10
+	}
+	// Cursors are hash+1, so that 0 can mean "start" and "done". Anything
+	// past the hash space can't have come from Scan: nothing is left there.
+	if cursor > math.MaxUint32+1 {
+		return nil, 0
+	}
+	var from uint32
+	if cursor > 0 {
+		from = uint32(cursor - 1)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := r.sortedKeys()
+	rest := index[sort.Search(len(index), func(i int) bool { return index[i].hash >= from }):]
+
+	// Like Redis, COUNT bounds how many keys are examined, not how many
+	// match, so a page can come back short (or empty) mid-iteration. A page
+	// never ends between two keys with the same hash, or the cursor couldn't
+	// say where the next one starts.
+	end := min(count, len(rest))
+	for end < len(rest) && end > 0 && rest[end].hash == rest[end-1].hash {
+		end++
+	}
+	var keys []string
+	for _, k := range rest[:end] {
+		if !r.expired(k.key) && (match == "" || matchGlob(match, k.key)) {
+			keys = append(keys, k.key)
+		}
+	}
+	var next uint64
+	if end < len(rest) {
+		next = uint64(rest[end].hash) + 1
+	}
+	return keys, next
+}
+
+// hashedKey is a key with its scanHash, as stored in the scan index
+type hashedKey struct {
+	hash uint32
+	key  string
+}
+
+// sortedKeys returns every key ordered by (scanHash, key), rebuilding the
+// index first if keys were added or removed since the last call. Callers
+// must hold at least the read lock, which keeps the index from going stale
+// while they use it.
+func (r *MiniRedis) sortedKeys() []hashedKey {
+	r.scanMu.Lock()
+	defer r.scanMu.Unlock()
+
+	if r.scanStale.Load() {
+		index := make([]hashedKey, 0, len(r.data))
+		for key := range r.data {
+			index = append(index, hashedKey{scanHash(key), key})
+		}
+		sort.Slice(index, func(i, j int) bool {
+			if index[i].hash != index[j].hash {
+				return index[i].hash < index[j].hash
+			}
+			return index[i].key < index[j].key
+		})
+		r.scanIndex = index
+		r.scanStale.Store(false)
+	}
+	return r.scanIndex
+}
+
+// scanHash is the FNV-1a hash that orders keys for Scan
+func scanHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// matchGlob reports whether s matches a Redis glob pattern: * and ? wildcards,
+// [abc] / [^abc] / [a-z] classes, and \ to escape the next character
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if s == "" {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return false // Unterminated class never matches
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			matched := false
+			for i := 0; i < len(class); i++ {
+				if i+2 < len(class) && class[i+1] == '-' {
+					if class[i] <= s[0] && s[0] <= class[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if class[i] == s[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			pattern, s = pattern[end+2:], s[1:]
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return s == ""
+}
+
 // Del deletes a key
 func (r *MiniRedis) Del(key string) bool {
+	defer r.observe("DEL", time.Now())
+	r.waitUnpaused(true)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.isExpired(key)
+
 	_, exists := r.data[key]
 	if exists {
-		delete(r.data, key)
-		delete(r.ttl, key)
-		fmt.Printf("DEL %s\n", key)
+		r.deleteKey(key)
+		r.logf("DEL %s\n", key)
 		return true
 	}
 	return false
 }
 
+// ErrNoSuchKey is returned by RENAME when the source key doesn't exist
+var ErrNoSuchKey = errors.New("ERR no such key")
+
+// Rename moves src to dst, overwriting dst whatever its type. The value and
+// TTL travel together: src's TTL (or lack of one) replaces dst's, so an old
+// expiry on dst can't delete the renamed value later. Renaming a key to
+// itself is a no-op.
+func (r *MiniRedis) Rename(src, dst string) error {
+	defer r.observe("RENAME", time.Now())
+	r.waitUnpaused(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.isExpired(src)
+	val, exists := r.data[src]
+	if !exists {
+		return ErrNoSuchKey
+	}
+	if src == dst {
+		return nil
+	}
+
+	r.setKey(dst, val)
+	if expireTime, ok := r.ttl[src]; ok {
+		r.ttl[dst] = expireTime
+	} else {
+		delete(r.ttl, dst)
+	}
+	r.deleteKey(src)
+
+	r.logf("RENAME %s %s\n", src, dst)
+	return nil
+}
+
 // DBSize returns the number of keys
 func (r *MiniRedis) DBSize() int {
+	defer r.observe("DBSIZE", time.Now())
+	r.waitUnpaused(false)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Clean up expired keys first
+	// Skip expired keys the sweep hasn't removed yet
 	count := 0
 	for key := range r.data {
-		if !r.isExpired(key) {
+		if !r.expired(key) {
 			count++
 		}
 	}
 
-	fmt.Printf("DBSIZE = %d\n", count)
+	r.logf("DBSIZE = %d\n", count)
 	return count
 }
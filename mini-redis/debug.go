@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Strings up to this long are embstr: header and bytes in one allocation
+const maxEmbstrLen = 44
+
+const (
+	sharedIntegers = 10000      // OBJ_SHARED_INTEGERS: 0-9999 are preallocated
+	sharedRefcount = 2147483647 // what OBJECT REFCOUNT reports for them
+)
+
+// encoding returns the OBJECT ENCODING Redis would use for a value under
+// these thresholds
+func (c encodingConfig) encoding(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil && len(v) <= 20 {
+			return "int"
+		}
+		if len(v) <= maxEmbstrLen {
+			return "embstr"
+		}
+		return "raw"
+	case map[string]string:
+		if len(v) > c.hashMaxListpackEntries {
+			return "hashtable"
+		}
+		for field, value := range v {
+			if len(field) > c.hashMaxListpackValue || len(value) > c.hashMaxListpackValue {
+				return "hashtable"
+			}
+		}
+		return "listpack"
+	case []string:
+		// One node's worth fits in a plain listpack
+		if len(v) > c.listMaxListpackSize {
+			return "quicklist"
+		}
+		return "listpack"
+	case map[string]bool:
+		allInts := len(v) <= c.setMaxIntsetEntries
+		for member := range v {
+			if !allInts {
+				break
+			}
+			_, err := strconv.ParseInt(member, 10, 64)
+			allInts = err == nil
+		}
+		if allInts {
+			return "intset"
+		}
+		if len(v) > c.setMaxListpackEntries {
+			return "hashtable"
+		}
+		for member := range v {
+			if len(member) > c.setMaxListpackValue {
+				return "hashtable"
+			}
+		}
+		return "listpack"
+	case map[string]float64:
+		if len(v) > c.zsetMaxListpackEntries {
+			return "skiplist"
+		}
+		for member := range v {
+			if len(member) > c.zsetMaxListpackValue {
+				return "skiplist"
+			}
+		}
+		return "listpack"
+	default:
+		return "unknown"
+	}
+}
+
+// refcount simulates Redis's shared integers: every string value that is an
+// integer from 0 to 9999 points at one preallocated object, which is never
+// freed, so Redis reports it with INT_MAX references. Everything else has
+// a single owner.
+func refcount(val interface{}) int64 {
+	if v, ok := val.(string); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && n >= 0 && n < sharedIntegers && strconv.FormatInt(n, 10) == v {
+			return sharedRefcount
+		}
+	}
+	return 1
+}
+
+// ObjectRefcount is OBJECT REFCOUNT: how many references the key's value has
+func (r *MiniRedis) ObjectRefcount(key string) (int64, error) {
+	defer r.observe("OBJECT", time.Now())
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	val, exists := r.data[key]
+	if !exists || r.expired(key) {
+		return 0, ErrNoSuchKey
+	}
+
+	n := refcount(val)
+	r.logf("OBJECT REFCOUNT %s = %d\n", key, n)
+	return n, nil
+}
+
+// serializedLength is a rough estimate of the value's size in an RDB file:
+// its bytes plus a one-byte length prefix per element
+func serializedLength(val interface{}) int {
+	n := 0
+	switch v := val.(type) {
+	case string:
+		n = len(v)
+	case map[string]string:
+		for field, value := range v {
+			n += 1 + len(field) + 1 + len(value)
+		}
+	case []string:
+		for _, value := range v {
+			n += 1 + len(value)
+		}
+	case map[string]bool:
+		for member := range v {
+			n += 1 + len(member)
+		}
+	case map[string]float64:
+		for member := range v {
+			n += 1 + len(member) + 8
+		}
+	}
+	return n
+}
+
+// DebugObject is DEBUG OBJECT: a one-line description of how a key's value
+// is stored. Lists also report their quicklist node count. Useful for
+// watching a small hash switch from listpack to hashtable as it grows.
+func (r *MiniRedis) DebugObject(key string) (string, error) {
+	defer r.observe("DEBUG", time.Now())
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	val, exists := r.data[key]
+	if !exists || r.expired(key) {
+		return "", ErrNoSuchKey
+	}
+
+	enc := r.config.encoding(val)
+	line := fmt.Sprintf("refcount:%d encoding:%s serializedlength:%d", refcount(val), enc, serializedLength(val))
+	if list, ok := val.([]string); ok && enc == "quicklist" {
+		nodeSize := r.config.listMaxListpackSize
+		nodes := (len(list) + nodeSize - 1) / nodeSize
+		line += fmt.Sprintf(" ql_nodes:%d ql_avg_node:%.2f ql_listpack_max:%d",
+			nodes, float64(len(list))/float64(nodes), nodeSize)
+	}
+
+	r.logf("DEBUG OBJECT %s = %s\n", key, line)
+	return line, nil
+}
+
+// DebugSleep is DEBUG SLEEP: it holds the write lock for d, so every other
+// command - reads included - waits behind it. That is what one slow command
+// (a KEYS *, a big DEL, a long Lua script) does to a single-threaded server.
+func (r *MiniRedis) DebugSleep(d time.Duration) {
+	defer r.observe("DEBUG", time.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logf("DEBUG SLEEP %.3f\n", d.Seconds())
+	time.Sleep(d)
+}
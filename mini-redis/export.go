@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// exportedKey is one key in the JSON dump
+type exportedKey struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+	// ExpiresAt is the absolute expiry (unix ms), not the remaining TTL, so a
+	// dump loaded later doesn't give keys a fresh lease. Omitted if no TTL.
+	ExpiresAt int64 `json:"expires_at_ms,omitempty"`
+}
+
+// typeName returns the Redis TYPE name for a stored value
+func typeName(val interface{}) string {
+	switch val.(type) {
+	case string:
+		return "string"
+	case map[string]string:
+		return "hash"
+	case []string:
+		return "list"
+	case map[string]bool:
+		return "set"
+	case map[string]float64:
+		return "zset"
+	default:
+		return "none"
+	}
+}
+
+// plainValue returns a stored value in the shape it is exported as. Sets
+// are stored as map[string]bool, so they become a sorted list of members.
+func plainValue(val interface{}) interface{} {
+	if set, ok := val.(map[string]bool); ok {
+		members := make([]string, 0, len(set))
+		for member := range set {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		return members
+	}
+	return val
+}
+
+// ExportJSON dumps every key with its type, value and expiry time as
+// indented JSON, so you can open the whole dataset in an editor.
+// (Real Redis persists to a compact binary RDB file instead.)
+func (r *MiniRedis) ExportJSON() ([]byte, error) {
+	r.waitUnpaused(false)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]exportedKey, 0, len(r.data))
+	for key, val := range r.data {
+		var expiresAt int64
+		if expireTime, ok := r.ttl[key]; ok {
+			if !now.Before(expireTime) {
+				continue // Expired, just not swept yet
+			}
+			expiresAt = expireTime.UnixMilli()
+		}
+
+		raw, err := json.Marshal(plainValue(val))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, exportedKey{Key: key, Type: typeName(r.data[key]), Value: raw, ExpiresAt: expiresAt})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+
+	return json.MarshalIndent(struct {
+		Keys []exportedKey `json:"keys"`
+	}{keys}, "", "  ")
+}
+
+// ExportByType groups every key by its TYPE - e.g. {"hash": [40 keys],
+// "zset": [3 keys]} - with each group sorted. It walks the keyspace with
+// SCAN, a page of 100 at a time, so the lock is never held for the whole
+// keyspace at once. Keys deleted mid-walk are left out.
+func (r *MiniRedis) ExportByType() (map[string][]string, error) {
+	defer r.observe("EXPORTBYTYPE", time.Now())
+	r.waitUnpaused(false)
+
+	groups := r.keysByType()
+	for t, keys := range groups {
+		r.logf("EXPORTBYTYPE %s: %d keys\n", t, len(keys))
+	}
+	return groups, nil
+}
+
+// keysByType is the walk behind ExportByType. It waits for nothing and
+// records no stats, so observers like the admin server can call it without
+// stalling under CLIENT PAUSE or showing up in the command stats.
+func (r *MiniRedis) keysByType() map[string][]string {
+	groups := make(map[string][]string)
+	var cursor uint64
+	for {
+		keys, next := r.scanPage(cursor, "", 100)
+
+		r.mu.RLock()
+		for _, key := range keys {
+			if val, exists := r.data[key]; exists && !r.expired(key) {
+				t := typeName(val)
+				groups[t] = append(groups[t], key)
+			}
+		}
+		r.mu.RUnlock()
+
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	for _, keys := range groups {
+		sort.Strings(keys)
+	}
+	return groups
+}
+
+// ImportJSON loads a dump produced by ExportJSON. Keys in the dump replace
+// existing keys with the same name. Expiry times are absolute, so a key that
+// had 5s left expires 5s after the export, and keys that expired while the
+// dump sat on disk are skipped (like Redis loading an RDB file).
+func (r *MiniRedis) ImportJSON(data []byte) error {
+	var dump struct {
+		Keys []exportedKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return err
+	}
+
+	// Decode everything before touching the dataset, so a bad dump changes nothing
+	values := make([]interface{}, len(dump.Keys))
+	for i, k := range dump.Keys {
+		var err error
+		switch k.Type {
+		case "string":
+			var v string
+			err = json.Unmarshal(k.Value, &v)
+			values[i] = v
+		case "hash":
+			v := map[string]string{}
+			err = json.Unmarshal(k.Value, &v)
+			values[i] = v
+		case "list":
+			var v []string
+			err = json.Unmarshal(k.Value, &v)
+			values[i] = v
+		case "set":
+			var members []string
+			err = json.Unmarshal(k.Value, &members)
+			set := make(map[string]bool, len(members))
+			for _, m := range members {
+				set[m] = true
+			}
+			values[i] = set
+		case "zset":
+			v := map[string]float64{}
+			err = json.Unmarshal(k.Value, &v)
+			values[i] = v
+		default:
+			err = fmt.Errorf("unknown type %q", k.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("key %q: %w", k.Key, err)
+		}
+	}
+
+	r.waitUnpaused(true)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	loaded := 0
+	for i, k := range dump.Keys {
+		var expireTime time.Time
+		if k.ExpiresAt > 0 {
+			expireTime = time.UnixMilli(k.ExpiresAt)
+			if !now.Before(expireTime) {
+				continue // Already expired
+			}
+		}
+
+		r.setKey(k.Key, values[i])
+		delete(r.ttl, k.Key)
+		if !expireTime.IsZero() {
+			r.ttl[k.Key] = expireTime
+		}
+		loaded++
+	}
+	r.logf("IMPORT %d keys (%d already expired)\n", loaded, len(dump.Keys)-loaded)
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Info returns INFO output for one section ("stats", "latencystats",
+// "keyspace"), or every section for "" / "all". The format
+// matches real Redis - "# Section" headers and field:value lines - so tools
+// that parse INFO can read it.
+func (r *MiniRedis) Info(section string) string {
+	section = strings.ToLower(section)
+	all := section == "" || section == "all"
+
+	var b strings.Builder
+	if all || section == "stats" {
+		r.infoStats(&b)
+	}
+	if all || section == "latencystats" {
+		if b.Len() > 0 {
+			b.WriteString("\r\n")
+		}
+		r.infoLatencyStats(&b)
+	}
+	if all || section == "keyspace" {
+		if b.Len() > 0 {
+			b.WriteString("\r\n")
+		}
+		r.infoKeyspace(&b)
+	}
+	return b.String()
+}
+
+// infoStats writes the stats section: commands run, read hits/misses and
+// WRONGTYPE errors. keyspace_hits / (hits + misses) is the cache hit rate.
+// (wrongtype_errors is MiniRedis's own; Redis 7 counts all error replies
+// under errorstat_WRONGTYPE instead.)
+func (r *MiniRedis) infoStats(b *strings.Builder) {
+	var total int64
+	for _, stats := range r.LatencyHistogram() {
+		total += stats.Calls
+	}
+
+	b.WriteString("# Stats\r\n")
+	fmt.Fprintf(b, "total_commands_processed:%d\r\n", total)
+	fmt.Fprintf(b, "keyspace_hits:%d\r\n", r.hits.Load())
+	fmt.Fprintf(b, "keyspace_misses:%d\r\n", r.misses.Load())
+	fmt.Fprintf(b, "wrongtype_errors:%d\r\n", r.wrongTypeErrors.Load())
+}
+
+// infoKeyspace writes the keyspace section: key and TTL counts for db0
+func (r *MiniRedis) infoKeyspace(b *strings.Builder) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys, expires := 0, 0
+	now := time.Now()
+	for key := range r.data {
+		expireTime, hasTTL := r.ttl[key]
+		if hasTTL && !now.Before(expireTime) {
+			continue
+		}
+		keys++
+		if hasTTL {
+			expires++
+		}
+	}
+
+	b.WriteString("# Keyspace\r\n")
+	if keys > 0 {
+		fmt.Fprintf(b, "db0:keys=%d,expires=%d\r\n", keys, expires)
+	}
+}
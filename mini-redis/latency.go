@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets; a
+// final bucket catches anything slower. Fixed buckets keep recording O(1):
+// one counter increment, no samples stored.
+var latencyBounds = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// LatencyStats is the latency histogram for one command
+type LatencyStats struct {
+	Calls    int64
+	Min, Max time.Duration
+	// Buckets[i] counts calls that took <= latencyBounds[i]; the last
+	// bucket counts calls slower than every bound
+	Buckets []int64
+}
+
+// observe records how long a command took. Call it first thing in a command
+// method: defer r.observe("GET", time.Now()). The time includes waiting for
+// the lock, so a slow command shows up in the latency of the ones it blocked.
+func (r *MiniRedis) observe(cmd string, start time.Time) {
+	d := time.Since(start)
+	bucket := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+
+	stats, ok := r.latency[cmd]
+	if !ok {
+		stats = &LatencyStats{Min: d, Buckets: make([]int64, len(latencyBounds)+1)}
+		r.latency[cmd] = stats
+	}
+	stats.Calls++
+	stats.Min = min(stats.Min, d)
+	stats.Max = max(stats.Max, d)
+	stats.Buckets[bucket]++
+}
+
+// LatencyHistogram is LATENCY HISTOGRAM: per-command stats for the given
+// commands, or every command seen so far if none are given
+func (r *MiniRedis) LatencyHistogram(commands ...string) map[string]LatencyStats {
+	r.latencyMu.Lock()
+	defer r.latencyMu.Unlock()
+
+	result := make(map[string]LatencyStats)
+	for cmd, stats := range r.latency {
+		if len(commands) > 0 && !containsFold(commands, cmd) {
+			continue
+		}
+		snapshot := *stats
+		snapshot.Buckets = append([]int64(nil), stats.Buckets...)
+		result[cmd] = snapshot
+	}
+	return result
+}
+
+// Percentile estimates the p-th percentile (0-100) as the upper bound of
+// the bucket it falls in - coarse, but all a fixed histogram can tell
+func (s LatencyStats) Percentile(p float64) time.Duration {
+	target := int64(float64(s.Calls)*p/100 + 0.5)
+	var seen int64
+	for i, n := range s.Buckets {
+		seen += n
+		if seen >= target && n > 0 {
+			if i < len(latencyBounds) {
+				return latencyBounds[i]
+			}
+			return s.Max
+		}
+	}
+	return s.Max
+}
+
+// infoLatencyStats writes the latencystats section, in real Redis's format
+func (r *MiniRedis) infoLatencyStats(b *strings.Builder) {
+	stats := r.LatencyHistogram()
+	commands := make([]string, 0, len(stats))
+	for cmd := range stats {
+		commands = append(commands, cmd)
+	}
+	sort.Strings(commands)
+
+	usec := func(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
+	b.WriteString("# Latencystats\r\n")
+	for _, cmd := range commands {
+		s := stats[cmd]
+		fmt.Fprintf(b, "latency_percentiles_usec_%s:p50=%.3f,p99=%.3f,p99.9=%.3f\r\n",
+			strings.ToLower(cmd), usec(s.Percentile(50)), usec(s.Percentile(99)), usec(s.Percentile(99.9)))
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"time"
+
+	goredis "github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -12,6 +18,7 @@ func main() {
 	fmt.Println()
 
 	redis := NewMiniRedis()
+	defer redis.Close()
 
 	// ===== DEMO 1: STRING OPERATIONS =====
 	fmt.Println("═══════════════════════════════════════════════════════════════")
@@ -27,8 +34,22 @@ func main() {
 		fmt.Printf("✓ Retrieved: %s\n", name)
 	}
 
+	for _, key := range []string{"user:1000:age", "user:1000:name"} {
+		if n, err := redis.ObjectRefcount(key); err == nil {
+			fmt.Printf("✓ OBJECT REFCOUNT %s: %d\n", key, n)
+		}
+	}
+
+	redis.LPush("user:1000:name", "oops") // A string, not a list: WRONGTYPE
+	stats := redis.Info("stats")
+	if i := strings.Index(stats, "wrongtype_errors:"); i >= 0 {
+		fmt.Printf("✓ INFO stats %s\n", strings.TrimSpace(stats[i:]))
+	}
+
 	fmt.Println("\n💡 In Redis, EVERYTHING is stored in a hash map (like Go's map[string]interface{})")
 	fmt.Println("   Strings are just string values in that map.")
+	fmt.Println("   Small integers (0-9999) are shared: every key holding \"30\" points at")
+	fmt.Println("   the same preallocated object, hence the huge refcount.")
 
 	time.Sleep(2 * time.Second)
 
@@ -46,8 +67,28 @@ func main() {
 		fmt.Printf("✓ User object: %v\n", hash)
 	}
 
+	redis.SetLogging(false)
+	for i := 0; i < 200; i++ {
+		redis.HSet("user:2000:prefs", fmt.Sprintf("pref:%d", i), "on")
+	}
+	redis.SetLogging(true)
+	for _, key := range []string{"user:2000", "user:2000:prefs"} {
+		if line, err := redis.DebugObject(key); err == nil {
+			fmt.Printf("✓ DEBUG OBJECT %s: %s\n", key, line)
+		}
+	}
+
+	// Lower the threshold below user:2000's 3 fields: same data, new encoding
+	redis.ConfigSet("hash-max-listpack-entries", "2")
+	if line, err := redis.DebugObject("user:2000"); err == nil {
+		fmt.Printf("✓ After CONFIG SET hash-max-listpack-entries 2: %s\n", line)
+	}
+	redis.ConfigSet("hash-max-listpack-entries", "128")
+
 	fmt.Println("\n💡 Hashes are just map[string]string stored as the value!")
 	fmt.Println("   Perfect for storing objects/structs.")
+	fmt.Println("   Real Redis packs small hashes into one listpack and only switches")
+	fmt.Println("   to a hash table past 128 fields (or a field over 64 bytes).")
 
 	time.Sleep(2 * time.Second)
 
@@ -68,8 +109,20 @@ func main() {
 		time.Sleep(500 * time.Millisecond)
 	}
 
+	redis.LPush("history", "/home", "/products", "/cart")
+	fmt.Println("\nBrowser history (LIFO - LPUSH + LPOP is a stack):")
+	for {
+		page, ok := redis.LPop("history")
+		if !ok {
+			break
+		}
+		fmt.Printf("  ← Back to: %s\n", page)
+	}
+
 	fmt.Println("\n💡 Lists are just []string slices!")
 	fmt.Println("   LPUSH adds to the left, RPOP removes from the right.")
+	fmt.Println("   RPUSH and LPOP are the mirror image: push and pop the same side")
+	fmt.Println("   for a stack, opposite sides for a queue.")
 
 	time.Sleep(2 * time.Second)
 
@@ -86,8 +139,16 @@ func main() {
 		fmt.Printf("✓ Unique tags: %v\n", members)
 	}
 
+	redis.SAdd("tags:post:1", "redis", "go", "cache")
+	redis.SAdd("tags:post:2", "redis", "go", "database")
+	if n, err := redis.SInterCard([]string{"tags:post:1", "tags:post:2"}, 2); err == nil && n == 2 {
+		fmt.Println("✓ Posts 1 and 2 share at least 2 tags (SINTERCARD LIMIT 2)")
+	}
+
 	fmt.Println("\n💡 Sets are map[string]bool where only keys matter!")
 	fmt.Println("   Automatically handles uniqueness.")
+	fmt.Println("   SINTERCARD counts the overlap without building it, probing the")
+	fmt.Println("   smallest set's members against the others and stopping at LIMIT.")
 
 	time.Sleep(2 * time.Second)
 
@@ -112,8 +173,18 @@ func main() {
 		time.Sleep(1 * time.Second)
 	}
 
+	// RENAME moves the TTL with the value and drops the destination's
+	redis.Set("report:draft", "v2")
+	redis.Expire("report:draft", 60)
+	redis.Set("report", "v1")
+	redis.Expire("report", 1)
+	redis.Rename("report:draft", "report")
+	fmt.Printf("\nAfter RENAME report:draft report: TTL(report) = %d (the draft's), TTL(report:draft) = %d\n",
+		redis.TTL("report"), redis.TTL("report:draft"))
+
 	fmt.Println("\n💡 Redis stores expiration times in a separate map[string]time.Time")
 	fmt.Println("   Background goroutine checks and deletes expired keys.")
+	fmt.Println("   So RENAME has to move the key's entry in both maps.")
 
 	time.Sleep(2 * time.Second)
 
@@ -140,12 +211,187 @@ func main() {
 	redis.Expire("leaderboard:daily", 86400) // 24 hours
 	fmt.Println("\n✓ Leaderboard will reset in 24 hours")
 
+	// The same scores in a sorted set, checked in one batch
+	redis.ZAdd("leaderboard:weekly", 100, "player1")
+	redis.ZAdd("leaderboard:weekly", 150, "player4")
+	players := []string{"player1", "player2", "player4"}
+	if scores, err := redis.ZMScore("leaderboard:weekly", players...); err == nil {
+		fmt.Println("\n📊 ZMSCORE (one call, nil = not ranked this week):")
+		for i, score := range scores {
+			if score == nil {
+				fmt.Printf("   %s: (nil)\n", players[i])
+			} else {
+				fmt.Printf("   %s: %g points\n", players[i], *score)
+			}
+		}
+	}
+
 	fmt.Println("\n💡 Real Redis would use SORTED SETS (ZADD/ZRANGE) for leaderboards")
 	fmt.Println("   This is simplified, but shows the concept!")
+	fmt.Println("   Batch commands (ZMSCORE, SMISMEMBER, HMGET) answer many")
+	fmt.Println("   lookups in one round trip instead of one call each.")
+
+	time.Sleep(2 * time.Second)
+
+	// ===== DEMO 7: SAME CODE, TWO BACKENDS =====
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" DEMO 7: Same Code, Two Backends (Store interface)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	ctx := context.Background()
+
+	fmt.Println("Business logic against MiniRedis (what a unit test would use):")
+	views, _ := recordPageView(ctx, NewMiniRedisStore(redis), "/home")
+	views, _ = recordPageView(ctx, NewMiniRedisStore(redis), "/home")
+	fmt.Printf("✓ /home views: %d\n", views)
+
+	fmt.Println("\nSame function against real Redis (if it's running):")
+	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379", DialTimeout: 200 * time.Millisecond})
+	if err := client.Ping(ctx).Err(); err != nil {
+		fmt.Println("  (real Redis not reachable, skipping - start it with: cd .. && make up)")
+	} else {
+		views, _ = recordPageView(ctx, NewGoRedisStore(client), "/home")
+		fmt.Printf("✓ /home views in real Redis: %d\n", views)
+	}
+	client.Close()
+
+	fmt.Println("\n💡 recordPageView only knows about Store, so it can't tell")
+	fmt.Println("   whether it's talking to a Go map or a real server.")
+
+	time.Sleep(2 * time.Second)
+
+	// ===== DEMO 8: INSPECTING STATE =====
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" DEMO 8: Inspecting State (JSON export/import)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	snapshot := NewMiniRedis()
+	defer snapshot.Close()
+	snapshot.Set("greeting", "hello")
+	snapshot.HSet("user:1", "name", "Alice")
+	snapshot.LPush("jobs", "job1", "job2")
+	snapshot.SAdd("tags", "redis", "go")
+	snapshot.Expire("greeting", 60)
+
+	dump, err := snapshot.ExportJSON()
+	if err != nil {
+		fmt.Printf("ERROR: export failed: %v\n", err)
+	} else {
+		fmt.Println(string(dump))
+
+		restored := NewMiniRedis()
+		defer restored.Close()
+		if err := restored.ImportJSON(dump); err != nil {
+			fmt.Printf("ERROR: import failed: %v\n", err)
+		} else if name, ok := restored.HGet("user:1", "name"); ok {
+			fmt.Printf("✓ Restored into a fresh instance: user:1 name = %s\n", name)
+		}
+	}
+
+	// The same data, live, over the admin API
+	if srv, err := snapshot.ServeAdmin("127.0.0.1:0"); err != nil {
+		fmt.Printf("ERROR: admin API: %v\n", err)
+	} else {
+		fmt.Printf("\nAdmin API on http://%s:\n", srv.Addr)
+		for _, path := range []string{"/keys?match=*e*", "/key/greeting", "/types"} {
+			resp, err := http.Get("http://" + srv.Addr + path)
+			if err != nil {
+				fmt.Printf("ERROR: GET %s: %v\n", path, err)
+				continue
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			fmt.Printf("  GET %-16s → %s", path, body)
+		}
+		srv.Close()
+	}
+
+	fmt.Println("\n💡 Real Redis saves a compact binary RDB file instead.")
+	fmt.Println("   JSON is bigger and slower, but you can read it.")
+	fmt.Println("   The admin API (/keys, /key/{name}, /types, /info, /stats) is for peeking at")
+	fmt.Println("   a running instance, one SCAN page at a time.")
+
+	time.Sleep(2 * time.Second)
+
+	// ===== DEMO 9: CLUSTER HASH SLOTS =====
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" DEMO 9: Cluster Hash Slots (CRC16 mod 16384)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	for _, key := range []string{"user:1:cart", "user:1:orders", "{user:1}:cart", "{user:1}:orders"} {
+		redis.ClusterKeySlot(key)
+	}
+	for _, slots := range redis.ClusterSlots() {
+		fmt.Printf("CLUSTER SLOTS: %d-%d → %s\n", slots.Start, slots.End, slots.Node)
+	}
+
+	fmt.Println("\n💡 Without a hash tag, related keys scatter across slots (and nodes).")
+	fmt.Println("   With {user:1}, only \"user:1\" is hashed, so they share a slot")
+	fmt.Println("   and can be used together in MULTI or a Lua script.")
+	fmt.Println("   This single node owns all 16384 slots.")
+
+	time.Sleep(2 * time.Second)
+
+	// ===== DEMO 10: LATENCY AND BLOCKING =====
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println(" DEMO 10: Latency and Blocking (logging off)")
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	fmt.Println()
+
+	bench := NewMiniRedis()
+	defer bench.Close()
+	bench.SetLogging(false) // fmt.Printf would dominate every measurement
+
+	// Some ordinary traffic plus a few huge LPUSH batches
+	for i := 0; i < 10_000; i++ {
+		bench.Set(fmt.Sprintf("key:%d", i), "value")
+		bench.Get(fmt.Sprintf("key:%d", i))
+		bench.LPush("queue", "task")
+		bench.RPop("queue")
+	}
+	values := make([]string, 8_000)
+	for i := range values {
+		values[i] = "v"
+	}
+	for i := 0; i < 3; i++ {
+		bench.Del("biglist")
+		bench.LPush("biglist", values...)
+	}
+
+	fmt.Println("INFO latencystats (every command is timed, in fixed buckets):")
+	fmt.Print(strings.ReplaceAll(bench.Info("latencystats"), "\r\n", "\n"))
+	lpush := bench.LatencyHistogram("LPUSH")["LPUSH"]
+	fmt.Printf("LATENCY HISTOGRAM lpush: calls=%d max=%v buckets=%v\n",
+		lpush.Calls, lpush.Max.Round(time.Microsecond), lpush.Buckets)
+	fmt.Println("   (the 8000-value batches land in the top buckets - too rare to move p99.9, but max shows them)")
+
+	fmt.Println("\nA GET issued while another client runs DEBUG SLEEP 0.2:")
+	sleeping := make(chan struct{})
+	go func() {
+		close(sleeping)
+		bench.DebugSleep(200 * time.Millisecond)
+	}()
+	<-sleeping
+	time.Sleep(10 * time.Millisecond) // Let DEBUG SLEEP take the lock first
+	fmt.Printf("  GET took %v\n", timeOp(func() { bench.Get("key:1") }).Round(time.Millisecond))
+
+	fmt.Println("\nDuring CLIENT PAUSE 200 WRITE:")
+	bench.ClientPause(200*time.Millisecond, PauseWrite)
+	fmt.Printf("  GET took %v\n", timeOp(func() { bench.Get("key:1") }).Round(time.Millisecond))
+	time.AfterFunc(50*time.Millisecond, bench.ClientUnpause)
+	fmt.Printf("  SET took %v (released by CLIENT UNPAUSE after 50ms)\n",
+		timeOp(func() { bench.Set("key:1", "v2") }).Round(time.Millisecond))
+
+	fmt.Println("\n💡 One slow command stalls everyone queued behind it.")
+	fmt.Println("   For ops/sec, run the benchmarks: go test -bench . -benchmem")
+	fmt.Println("   (BenchmarkLPushBatch shows LPUSH is O(n²) here; real Redis quicklists make it O(1) per value)")
 
 	time.Sleep(2 * time.Second)
 
-	// ===== DEMO 7: WHY REDIS IS FAST =====
+	// ===== DEMO 11: WHY REDIS IS FAST =====
 	fmt.Println("═══════════════════════════════════════════════════════════════")
 	fmt.Println(" WHY IS REDIS SO FAST?")
 	fmt.Println("═══════════════════════════════════════════════════════════════")
@@ -201,3 +447,25 @@ func main() {
 	fmt.Println("║                    Happy Learning! 🎉                        ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 }
+
+// recordPageView is example business logic: it only depends on Store
+func recordPageView(ctx context.Context, store Store, page string) (int64, error) {
+	views, err := store.Incr(ctx, "views:"+page)
+	if err != nil {
+		return 0, err
+	}
+	if views == 1 {
+		// First view today - start the daily window
+		if _, err := store.Expire(ctx, "views:"+page, 24*time.Hour); err != nil {
+			return 0, err
+		}
+	}
+	return views, nil
+}
+
+// timeOp returns how long one call to op took
+func timeOp(op func()) time.Duration {
+	start := time.Now()
+	op()
+	return time.Since(start)
+}
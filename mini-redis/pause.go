@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// PauseMode says which commands CLIENT PAUSE holds back
+type PauseMode int
+
+const (
+	// PauseWrite holds writes; reads keep running (CLIENT PAUSE ... WRITE)
+	PauseWrite PauseMode = iota
+	// PauseAll holds every data command, reads included
+	PauseAll
+)
+
+// pauseState is the CLIENT PAUSE gate. It has its own lock, because a
+// paused command waits before it takes MiniRedis's lock.
+type pauseState struct {
+	mu    sync.Mutex
+	all   bool
+	until time.Time
+	// lifted is closed when the pause ends; nil while not paused
+	lifted chan struct{}
+}
+
+// ClientPause is CLIENT PAUSE: for d, commands the mode covers block until
+// the pause ends, then run. Typical uses are a failover (stop writes, let
+// a replica catch up, switch over) or taking a consistent snapshot. Keys
+// don't expire during the pause either, so the data holds still. Pausing
+// again while paused extends the pause and can widen WRITE to ALL.
+func (r *MiniRedis) ClientPause(d time.Duration, mode PauseMode) {
+	p := &r.pause
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if p.lifted == nil {
+		p.lifted = make(chan struct{})
+		p.all = false
+		p.until = time.Time{} // An earlier pause may have been lifted early
+	}
+	p.all = p.all || mode == PauseAll
+	if until.After(p.until) {
+		p.until = until
+		time.AfterFunc(d, r.endPauseIfDue)
+	}
+
+	scope := "WRITE"
+	if p.all {
+		scope = "ALL"
+	}
+	r.logf("CLIENT PAUSE %d %s\n", d.Milliseconds(), scope)
+}
+
+// ClientUnpause is CLIENT UNPAUSE: it ends the pause early, releasing
+// every blocked command
+func (r *MiniRedis) ClientUnpause() {
+	p := &r.pause
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lifted != nil {
+		close(p.lifted)
+		p.lifted = nil
+	}
+	r.logf("CLIENT UNPAUSE\n")
+}
+
+// endPauseIfDue lifts the pause once its (possibly extended) end has passed
+func (r *MiniRedis) endPauseIfDue() {
+	p := &r.pause
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lifted != nil && !time.Now().Before(p.until) {
+		close(p.lifted)
+		p.lifted = nil
+	}
+}
+
+// waitUnpaused blocks a command while a pause covers it. Call it before
+// taking r.mu. There are no connections here to cancel, so a blocked
+// command waits for the pause to end or be lifted.
+func (r *MiniRedis) waitUnpaused(write bool) {
+	p := &r.pause
+	for {
+		p.mu.Lock()
+		lifted := p.lifted
+		blocked := lifted != nil && (write || p.all)
+		p.mu.Unlock()
+		if !blocked {
+			return
+		}
+		<-lifted
+	}
+}
+
+// paused reports whether any pause is in effect
+func (r *MiniRedis) paused() bool {
+	r.pause.mu.Lock()
+	defer r.pause.mu.Unlock()
+	return r.pause.lifted != nil
+}
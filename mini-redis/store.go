@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is the subset of Redis commands most application code needs.
+// Write business logic against Store, then pass NewMiniRedisStore in unit
+// tests (no server needed) and NewGoRedisStore in production.
+// Both return ErrNil for a missing key or field, ErrWrongType when the
+// key holds another type and ErrNotInteger when INCR hits a non-integer.
+type Store interface {
+	Set(ctx context.Context, key, value string) error
+	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, key string) (bool, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	HSet(ctx context.Context, key, field, value string) error
+	HGet(ctx context.Context, key, field string) (string, error)
+	LPush(ctx context.Context, key string, values ...string) error
+	RPop(ctx context.Context, key string) (string, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// TTL returns -1 if the key has no TTL and -2 if it doesn't exist,
+	// matching go-redis
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ===== MINI-REDIS ADAPTER =====
+
+type miniRedisStore struct {
+	r *MiniRedis
+}
+
+// NewMiniRedisStore adapts an in-memory MiniRedis to Store
+func NewMiniRedisStore(r *MiniRedis) Store {
+	return &miniRedisStore{r: r}
+}
+
+func (s *miniRedisStore) Set(ctx context.Context, key, value string) error {
+	s.r.Set(key, value)
+	return nil
+}
+
+// The public MiniRedis methods report a wrong type the same way as a missing
+// key (!ok, or doing nothing), so the adapter calls their error-returning twins
+
+func (s *miniRedisStore) Get(ctx context.Context, key string) (string, error) {
+	return s.r.get(key)
+}
+
+func (s *miniRedisStore) Del(ctx context.Context, key string) (bool, error) {
+	return s.r.Del(key), nil
+}
+
+func (s *miniRedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.r.incr(key)
+}
+
+func (s *miniRedisStore) HSet(ctx context.Context, key, field, value string) error {
+	return s.r.hset(key, field, value)
+}
+
+func (s *miniRedisStore) HGet(ctx context.Context, key, field string) (string, error) {
+	return s.r.hget(key, field)
+}
+
+func (s *miniRedisStore) LPush(ctx context.Context, key string, values ...string) error {
+	return s.r.lpush(key, values...)
+}
+
+func (s *miniRedisStore) RPop(ctx context.Context, key string) (string, error) {
+	return s.r.rpop(key)
+}
+
+func (s *miniRedisStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	// PEXPIRE, like go-redis does for sub-second TTLs
+	return s.r.PExpire(key, ttl.Milliseconds()), nil
+}
+
+func (s *miniRedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	secs := s.r.TTL(key)
+	if secs < 0 {
+		return time.Duration(secs), nil
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// ===== GO-REDIS ADAPTER =====
+
+type goRedisStore struct {
+	c *redis.Client
+}
+
+// NewGoRedisStore adapts a real Redis client to Store
+func NewGoRedisStore(c *redis.Client) Store {
+	return &goRedisStore{c: c}
+}
+
+// storeErr maps redis.Nil, WRONGTYPE and not-an-integer replies to ErrNil,
+// ErrWrongType and ErrNotInteger, so callers check the same sentinels
+// whichever backend they run on
+func storeErr(err error) error {
+	var redisErr redis.Error
+	switch {
+	case errors.Is(err, redis.Nil):
+		return ErrNil
+	case errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "WRONGTYPE "):
+		return ErrWrongType
+	case errors.As(err, &redisErr) && redisErr.Error() == ErrNotInteger.Error():
+		return ErrNotInteger
+	}
+	return err
+}
+
+func (s *goRedisStore) Set(ctx context.Context, key, value string) error {
+	return s.c.Set(ctx, key, value, 0).Err()
+}
+
+func (s *goRedisStore) Get(ctx context.Context, key string) (string, error) {
+	val, err := s.c.Get(ctx, key).Result()
+	return val, storeErr(err)
+}
+
+func (s *goRedisStore) Del(ctx context.Context, key string) (bool, error) {
+	n, err := s.c.Del(ctx, key).Result()
+	return n == 1, err
+}
+
+func (s *goRedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := s.c.Incr(ctx, key).Result()
+	return n, storeErr(err)
+}
+
+func (s *goRedisStore) HSet(ctx context.Context, key, field, value string) error {
+	return storeErr(s.c.HSet(ctx, key, field, value).Err())
+}
+
+func (s *goRedisStore) HGet(ctx context.Context, key, field string) (string, error) {
+	val, err := s.c.HGet(ctx, key, field).Result()
+	return val, storeErr(err)
+}
+
+func (s *goRedisStore) LPush(ctx context.Context, key string, values ...string) error {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return storeErr(s.c.LPush(ctx, key, args...).Err())
+}
+
+func (s *goRedisStore) RPop(ctx context.Context, key string) (string, error) {
+	val, err := s.c.RPop(ctx, key).Result()
+	return val, storeErr(err)
+}
+
+func (s *goRedisStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.c.Expire(ctx, key, ttl).Result()
+}
+
+func (s *goRedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.c.TTL(ctx, key).Result()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TestStoreBackends runs the same business logic and error checks against
+// both Store implementations, so the in-memory one can't drift from what
+// real Redis does. The go-redis half needs a server on localhost:6379
+// (cd .. && make up) and is skipped without one.
+func TestStoreBackends(t *testing.T) {
+	t.Run("mini-redis", func(t *testing.T) {
+		r := NewMiniRedis()
+		r.SetLogging(false)
+		t.Cleanup(r.Close)
+		testStore(t, NewMiniRedisStore(r))
+	})
+
+	t.Run("go-redis", func(t *testing.T) {
+		client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379", DialTimeout: 200 * time.Millisecond})
+		t.Cleanup(func() { client.Close() })
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			t.Skipf("real Redis not reachable: %v", err)
+		}
+		testStore(t, NewGoRedisStore(client))
+	})
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	// Unique keys, so a shared Redis doesn't carry state between runs
+	prefix := fmt.Sprintf("store-test:%d:", time.Now().UnixNano())
+	page := "/" + prefix + "home"
+	str, hash, list := prefix+"str", prefix+"hash", prefix+"list"
+	t.Cleanup(func() {
+		for _, key := range []string{"views:" + page, str, hash, list} {
+			store.Del(ctx, key)
+		}
+	})
+
+	// Business logic: the first view starts a daily window, later ones don't
+	for want := int64(1); want <= 2; want++ {
+		views, err := recordPageView(ctx, store, page)
+		if err != nil || views != want {
+			t.Fatalf("recordPageView = %d, %v; want %d, nil", views, err, want)
+		}
+	}
+	if ttl, err := store.TTL(ctx, "views:"+page); err != nil || ttl <= 23*time.Hour {
+		t.Errorf("views TTL = %v, %v; want about 24h", ttl, err)
+	}
+
+	// Missing keys and fields are ErrNil
+	if _, err := store.Get(ctx, str); !errors.Is(err, ErrNil) {
+		t.Errorf("Get(missing) error = %v; want ErrNil", err)
+	}
+	if _, err := store.RPop(ctx, list); !errors.Is(err, ErrNil) {
+		t.Errorf("RPop(missing) error = %v; want ErrNil", err)
+	}
+	if err := store.HSet(ctx, hash, "name", "ada"); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if _, err := store.HGet(ctx, hash, "age"); !errors.Is(err, ErrNil) {
+		t.Errorf("HGet(missing field) error = %v; want ErrNil", err)
+	}
+
+	// Commands against another type are ErrWrongType
+	if err := store.Set(ctx, str, "not a number"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := store.Get(ctx, hash); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Get(hash) error = %v; want ErrWrongType", err)
+	}
+	if _, err := store.HGet(ctx, str, "name"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("HGet(string) error = %v; want ErrWrongType", err)
+	}
+	if err := store.HSet(ctx, str, "name", "ada"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("HSet(string) error = %v; want ErrWrongType", err)
+	}
+	if err := store.LPush(ctx, hash, "a"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("LPush(hash) error = %v; want ErrWrongType", err)
+	}
+	if _, err := store.RPop(ctx, str); !errors.Is(err, ErrWrongType) {
+		t.Errorf("RPop(string) error = %v; want ErrWrongType", err)
+	}
+	if _, err := store.Incr(ctx, hash); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Incr(hash) error = %v; want ErrWrongType", err)
+	}
+	if _, err := store.Incr(ctx, str); !errors.Is(err, ErrNotInteger) {
+		t.Errorf("Incr(non-integer) error = %v; want ErrNotInteger", err)
+	}
+
+	// Lists pop in push order from the other end
+	if err := store.LPush(ctx, list, "a", "b"); err != nil {
+		t.Fatalf("LPush: %v", err)
+	}
+	if val, err := store.RPop(ctx, list); err != nil || val != "a" {
+		t.Errorf("RPop = %q, %v; want \"a\", nil", val, err)
+	}
+}